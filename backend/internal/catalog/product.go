@@ -0,0 +1,194 @@
+package catalog
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"gemini/backend/internal/models"
+	"gemini/backend/internal/search"
+	"gemini/backend/internal/storage"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// googleProductURL is the Google Shopping page of a product ID
+const googleProductURL = "https://www.google.com/shopping/product/"
+
+// ErrNotFound is returned when neither Postgres nor Meilisearch has the product
+var ErrNotFound = errors.New("product not found")
+
+// GetProduct returns the full product card. Postgres is the source of truth, Meilisearch serves
+// the card when Postgres doesn't have the product and fills what only the index stores,
+// like the Google product ID. Fields the two stores disagree on are reported as drift.
+func GetProduct(db *sql.DB, client meilisearch.ServiceManager, productID string) (*models.ProductDetail, error) {
+	stored, dbErr := storage.GetProduct(db, productID)
+	if errors.Is(dbErr, sql.ErrNoRows) {
+		dbErr = nil
+	} else if dbErr != nil {
+		log.Printf("Error getting product %s from Postgres: %v", productID, dbErr)
+	}
+
+	indexed, meiliErr := search.GetProductDetail(client, productID)
+	if errors.Is(meiliErr, search.ErrProductNotFound) {
+		meiliErr = nil
+	} else if meiliErr != nil {
+		log.Printf("Error getting product %s from Meilisearch: %v", productID, meiliErr)
+	}
+
+	if stored == nil && indexed == nil {
+		if dbErr != nil || meiliErr != nil {
+			return nil, fmt.Errorf("error getting product %s: %w", productID, errors.Join(dbErr, meiliErr))
+		}
+		return nil, ErrNotFound
+	}
+
+	var drift []models.DriftField
+	if stored != nil {
+		completeFeatures(stored)
+	}
+	if indexed != nil {
+		completeFeatures(indexed)
+	}
+	if stored != nil && indexed != nil {
+		drift = compare(stored, indexed)
+	}
+
+	product := stored
+	if product == nil {
+		product = indexed
+	} else if indexed != nil {
+		// Google ID пишет в индекс только бэкенд, в Postgres его нет
+		if product.GoogleProductID == "" {
+			product.GoogleProductID = indexed.GoogleProductID
+		}
+		if len(product.Images) == 0 {
+			product.Images = indexed.Images
+		}
+	}
+
+	product.Sync = models.SyncStatus{Postgres: stored != nil, Meilisearch: indexed != nil, Drift: drift}
+	if len(drift) > 0 {
+		fields := make([]string, len(drift))
+		for i, field := range drift {
+			fields[i] = field.Field
+		}
+		log.Printf("Product %s drifted between Postgres and Meilisearch: %s", productID, strings.Join(fields, ", "))
+	}
+
+	offers, err := storage.GetOffers(db, productID)
+	if err != nil {
+		log.Printf("Error getting offers of product %s: %v", productID, err)
+	}
+	if len(offers) > 0 {
+		product.OfferCount = len(offers)
+	}
+	product.BestPrice = bestPrice(product.PriceEUR, offers)
+
+	// Семья ищется в индексе, как и в результатах поиска
+	groupID := product.GroupID
+	if groupID == "" && indexed != nil {
+		groupID = indexed.GroupID
+	}
+	product.Variants, err = search.FamilyVariants(client, groupID)
+	if err != nil {
+		log.Printf("Error getting variants of product %s: %v", productID, err)
+	}
+
+	if product.GoogleProductID != "" {
+		product.GoogleProductURL = googleProductURL + url.PathEscape(product.GoogleProductID)
+	}
+	if product.Images == nil {
+		product.Images = []models.ProductImage{}
+	}
+	if product.CategoryPath == nil {
+		product.CategoryPath = []string{}
+	}
+
+	return product, nil
+}
+
+// completeFeatures derives the "name: value" feature list from the specs or the other way round,
+// whichever the store doesn't keep
+func completeFeatures(product *models.ProductDetail) {
+	if len(product.Features) == 0 {
+		product.Features = make([]string, 0, len(product.Specs))
+		for name, value := range product.Specs {
+			product.Features = append(product.Features, name+": "+value)
+		}
+	}
+	sort.Strings(product.Features)
+
+	if len(product.Specs) == 0 {
+		product.Specs = make(map[string]string, len(product.Features))
+		for _, feature := range product.Features {
+			if name, value, ok := strings.Cut(feature, ": "); ok {
+				product.Specs[name] = value
+			}
+		}
+	}
+}
+
+// compare returns the synchronized fields that differ between the Postgres and Meilisearch cards
+func compare(stored, indexed *models.ProductDetail) []models.DriftField {
+	var drift []models.DriftField
+	add := func(field string, differs bool, postgres, meili interface{}) {
+		if differs {
+			drift = append(drift, models.DriftField{Field: field, Postgres: postgres, Meilisearch: meili})
+		}
+	}
+
+	add("title", stored.Title != indexed.Title, stored.Title, indexed.Title)
+	add("category", stored.Category != indexed.Category, stored.Category, indexed.Category)
+	add("category_path", !slices.Equal(stored.CategoryPath, indexed.CategoryPath), stored.CategoryPath, indexed.CategoryPath)
+	add("features", !slices.Equal(stored.Features, indexed.Features), stored.Features, indexed.Features)
+	add("brand", stored.Brand != indexed.Brand, stored.Brand, indexed.Brand)
+	add("gtin", stored.GTIN != indexed.GTIN, stored.GTIN, indexed.GTIN)
+	add("group_id", stored.GroupID != indexed.GroupID, stored.GroupID, indexed.GroupID)
+	add("image_hash", mainImageHash(stored) != mainImageHash(indexed), mainImageHash(stored), mainImageHash(indexed))
+	// Цены округлены до центов в Postgres
+	add("price_eur", math.Abs(stored.PriceEUR-indexed.PriceEUR) >= 0.01, stored.PriceEUR, indexed.PriceEUR)
+
+	return drift
+}
+
+// bestPrice returns the cheapest in-stock offer, the listed price if no offer has a price
+func bestPrice(listedEUR float64, offers []models.Offer) *models.BestPrice {
+	var best *models.Offer
+	for i := range offers {
+		offer := &offers[i]
+		if offer.PriceEUR <= 0 || offer.Availability != "In stock" {
+			continue
+		}
+		if best == nil || offer.PriceEUR < best.PriceEUR {
+			best = offer
+		}
+	}
+
+	if best != nil {
+		return &models.BestPrice{
+			PriceEUR: best.PriceEUR,
+			Price:    best.Price,
+			Currency: best.Currency,
+			Merchant: best.Merchant,
+			Link:     best.Link,
+		}
+	}
+	if listedEUR > 0 {
+		return &models.BestPrice{PriceEUR: listedEUR}
+	}
+	return nil
+}
+
+func mainImageHash(product *models.ProductDetail) string {
+	if len(product.Images) == 0 {
+		return ""
+	}
+	return product.Images[0].Hash
+}
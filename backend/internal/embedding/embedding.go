@@ -0,0 +1,32 @@
+package embedding
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Embedder turns texts into vectors for semantic search
+type Embedder interface {
+	// Name identifies the embedder, vectors of different embedders are not comparable
+	Name() string
+	// Dimensions returns the vector size
+	Dimensions() int
+	// Embed returns one vector per text in the same order
+	Embed(texts []string) ([][]float32, error)
+}
+
+// NewFromEnv creates the embedder selected by EMBEDDER: "gemini" (default), "stub" or "none".
+// It returns nil when semantic search is disabled.
+func NewFromEnv(geminiKeys []string) (Embedder, error) {
+	switch strings.ToLower(os.Getenv("EMBEDDER")) {
+	case "", "gemini":
+		return NewGemini(geminiKeys)
+	case "stub":
+		return NewStub(StubDimensions), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown embedder: %s", os.Getenv("EMBEDDER"))
+	}
+}
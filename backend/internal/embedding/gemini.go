@@ -0,0 +1,91 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+const (
+	// geminiModel is the Gemini text embedding model
+	geminiModel = "text-embedding-004"
+	// geminiDimensions is the vector size of geminiModel
+	geminiDimensions = 768
+	// geminiBatchSize is the maximum number of texts per batch request
+	geminiBatchSize = 100
+)
+
+// Gemini embeds texts with the Gemini embedding model, rotating over the API keys
+type Gemini struct {
+	mu      sync.Mutex
+	models  []*genai.EmbeddingModel
+	current int
+}
+
+// NewGemini creates a Gemini embedder with a client per API key
+func NewGemini(apiKeys []string) (*Gemini, error) {
+	g := &Gemini{}
+	for _, apiKey := range apiKeys {
+		client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+		if err != nil {
+			log.Printf("Failed to create Gemini embedding client: %v", err)
+			continue
+		}
+		g.models = append(g.models, client.EmbeddingModel(geminiModel))
+	}
+	if len(g.models) == 0 {
+		return nil, fmt.Errorf("no valid Gemini API keys for embeddings")
+	}
+	return g, nil
+}
+
+// Name returns the embedding model name
+func (g *Gemini) Name() string {
+	return "gemini/" + geminiModel
+}
+
+// Dimensions returns the vector size of the model
+func (g *Gemini) Dimensions() int {
+	return geminiDimensions
+}
+
+// Embed embeds texts in batches
+func (g *Gemini) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+
+	for start := 0; start < len(texts); start += geminiBatchSize {
+		end := min(start+geminiBatchSize, len(texts))
+
+		model := g.nextModel()
+		batch := model.NewBatch()
+		for _, text := range texts[start:end] {
+			batch.AddContent(genai.Text(text))
+		}
+
+		resp, err := model.BatchEmbedContents(context.Background(), batch)
+		if err != nil {
+			return nil, fmt.Errorf("gemini embedding failed: %w", err)
+		}
+		if len(resp.Embeddings) != end-start {
+			return nil, fmt.Errorf("gemini returned %d embeddings for %d texts", len(resp.Embeddings), end-start)
+		}
+
+		for _, embedding := range resp.Embeddings {
+			vectors = append(vectors, embedding.Values)
+		}
+	}
+
+	return vectors, nil
+}
+
+func (g *Gemini) nextModel() *genai.EmbeddingModel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	model := g.models[g.current]
+	g.current = (g.current + 1) % len(g.models)
+	return model
+}
@@ -0,0 +1,78 @@
+package embedding
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// StubDimensions is the vector size of the stub embedder from NewFromEnv
+const StubDimensions = 256
+
+// Stub is a deterministic local embedder for tests and development without API keys.
+// Words are hashed into vector buckets, so texts sharing words are similar.
+type Stub struct {
+	dimensions int
+}
+
+// NewStub creates a stub embedder with vectors of the given size
+func NewStub(dimensions int) *Stub {
+	return &Stub{dimensions: dimensions}
+}
+
+// Name returns the stub name including its size
+func (s *Stub) Name() string {
+	return fmt.Sprintf("stub/%d", s.dimensions)
+}
+
+// Dimensions returns the vector size
+func (s *Stub) Dimensions() int {
+	return s.dimensions
+}
+
+// Embed returns normalized word-hash vectors
+func (s *Stub) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = s.embed(text)
+	}
+	return vectors, nil
+}
+
+func (s *Stub) embed(text string) []float32 {
+	vector := make([]float32, s.dimensions)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		sum := h.Sum32()
+
+		// Знак из старшего бита уменьшает влияние коллизий
+		sign := float32(1)
+		if sum&(1<<31) != 0 {
+			sign = -1
+		}
+		vector[int(sum%uint32(s.dimensions))] += sign
+	}
+
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value * value)
+	}
+	if norm == 0 {
+		// Пустой текст: единичный вектор, нулевой нельзя сравнивать
+		vector[0] = 1
+		return vector
+	}
+
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vector {
+		vector[i] *= scale
+	}
+	return vector
+}
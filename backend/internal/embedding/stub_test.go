@@ -0,0 +1,55 @@
+package embedding
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStubDeterministic(t *testing.T) {
+	stub := NewStub(StubDimensions)
+
+	vectors, err := stub.Embed([]string{"Apple iPhone 15 128GB", "Apple iPhone 15 128GB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := NewStub(StubDimensions).Embed([]string{"Apple iPhone 15 128GB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range vectors[0] {
+		if vectors[0][i] != vectors[1][i] || vectors[0][i] != again[0][i] {
+			t.Fatalf("vectors of the same text differ at %d", i)
+		}
+	}
+}
+
+func TestStubVectors(t *testing.T) {
+	stub := NewStub(StubDimensions)
+
+	vectors, err := stub.Embed([]string{"Apple iPhone 15", "apple IPHONE 15 case", "Samsung washing machine", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, vector := range vectors {
+		if len(vector) != StubDimensions {
+			t.Fatalf("vector %d: expected %d dimensions, got %d", i, StubDimensions, len(vector))
+		}
+		if norm := dot(vector, vector); math.Abs(norm-1) > 1e-5 {
+			t.Errorf("vector %d: expected unit length, got %f", i, norm)
+		}
+	}
+
+	if similar, different := dot(vectors[0], vectors[1]), dot(vectors[0], vectors[2]); similar <= different {
+		t.Errorf("texts sharing words should be closer: %f <= %f", similar, different)
+	}
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,79 @@
+package images
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"time"
+)
+
+// Sizes served from the image store, matching the ones generated by the parser
+const (
+	SizeOriginal = "original"
+	SizeMedium   = "medium"
+	SizeThumb    = "thumb"
+)
+
+// originalExtensions are the formats the parser stores originals in
+var originalExtensions = []string{"jpg", "png", "webp", "gif", "bmp", "avif"}
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Store reads objects written by the parser under keys like "ab/abcd…/medium.webp"
+type Store interface {
+	// Open returns the content of an object, os.ErrNotExist if it is missing
+	Open(key string) (io.ReadSeekCloser, time.Time, error)
+	// Exists reports whether an object is present
+	Exists(key string) bool
+}
+
+// NewStore creates the image store selected by IMAGE_STORE ("filesystem" or "s3")
+func NewStore() (Store, error) {
+	switch os.Getenv("IMAGE_STORE") {
+	case "", "filesystem":
+		dir := os.Getenv("IMAGES_DIR")
+		if dir == "" {
+			dir = "/images"
+		}
+		return NewFileStore(dir), nil
+	case "s3":
+		return NewS3Store(S3ConfigFromEnv())
+	default:
+		return nil, fmt.Errorf("unknown image store: %s", os.Getenv("IMAGE_STORE"))
+	}
+}
+
+// ResolveKey returns the object key of an image in the content-addressed store written by the parser.
+// Resized sizes are served as WebP when the client accepts it and as JPEG otherwise.
+func ResolveKey(store Store, hash, size string, webp bool) (string, error) {
+	if !hashPattern.MatchString(hash) {
+		return "", fmt.Errorf("invalid image hash")
+	}
+
+	dir := path.Join(hash[:2], hash)
+
+	switch size {
+	case SizeOriginal:
+		for _, ext := range originalExtensions {
+			if key := path.Join(dir, SizeOriginal+"."+ext); store.Exists(key) {
+				return key, nil
+			}
+		}
+		return "", os.ErrNotExist
+	case SizeMedium, SizeThumb:
+		if webp {
+			if key := path.Join(dir, size+".webp"); store.Exists(key) {
+				return key, nil
+			}
+		}
+		if key := path.Join(dir, size+".jpg"); store.Exists(key) {
+			return key, nil
+		}
+		// Для форматов без декодера (AVIF) есть только оригинал
+		return ResolveKey(store, hash, SizeOriginal, webp)
+	default:
+		return "", fmt.Errorf("unknown image size: %s", size)
+	}
+}
@@ -0,0 +1,122 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// storeTimeout limits a single object store request
+const storeTimeout = 30 * time.Second
+
+// FileStore reads objects from files below a directory shared with the parser
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a filesystem image store
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Open opens the object file
+func (s *FileStore) Open(key string) (io.ReadSeekCloser, time.Time, error) {
+	file, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, time.Time{}, err
+	}
+	return file, info.ModTime(), nil
+}
+
+// Exists reports whether the object file is present
+func (s *FileStore) Exists(key string) bool {
+	info, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(key)))
+	return err == nil && !info.IsDir()
+}
+
+// S3Config contains S3-compatible storage settings
+type S3Config struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+// S3ConfigFromEnv reads S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY, S3_BUCKET, S3_REGION and S3_USE_SSL
+func S3ConfigFromEnv() S3Config {
+	cfg := S3Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		Region:    os.Getenv("S3_REGION"),
+		UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+	}
+	if cfg.Bucket == "" {
+		cfg.Bucket = "images"
+	}
+	return cfg
+}
+
+// S3Store reads objects from a bucket of an S3-compatible storage such as MinIO
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store creates an S3 image store
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return &S3Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+// Open returns the object content, reads are made lazily by the returned object
+func (s *S3Store) Open(key string) (io.ReadSeekCloser, time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, time.Time{}, os.ErrNotExist
+		}
+		return nil, time.Time{}, err
+	}
+
+	// Объект читается после возврата из Open, поэтому без таймаута запроса
+	object, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return object, stat.LastModified, nil
+}
+
+// Exists reports whether the object is present in the bucket
+func (s *S3Store) Exists(key string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	_, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	return err == nil
+}
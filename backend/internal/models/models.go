@@ -1,10 +1,57 @@
 package models
 
-// SearchRequest represents the expected JSON structure of a search query
+import "time"
+
+// SearchRequest represents the expected JSON structure of a search query.
+// Normalized is the normalized query of a previous response, sending it back
+// refines the results with Filters without calling the AI normalizer again.
+// Facets lists the attributes to return value distributions and stats for.
+// Page starts at 1, Sort is relevance, price_asc, price_desc, popularity or newest.
+// SemanticRatio blends keyword (0) and semantic (1) scores, the server default if empty.
 type SearchRequest struct {
-	Query  string `json:"query"`
-	Lang   string `json:"lang"`
-	Region string `json:"region"`
+	Query         string              `json:"query"`
+	Lang          string              `json:"lang"`
+	Region        string              `json:"region"`
+	Normalized    *NormalizedResponse `json:"normalized,omitempty"`
+	Filters       SearchFilters       `json:"filters"`
+	Facets        []string            `json:"facets,omitempty"`
+	Page          int                 `json:"page,omitempty"`
+	PageSize      int                 `json:"page_size,omitempty"`
+	Sort          string              `json:"sort,omitempty"`
+	SemanticRatio *float64            `json:"semantic_ratio,omitempty"`
+}
+
+// SearchFilters are explicit filters chosen by the user in addition to the AI filter.
+// Attributes are feature values like "color: Blue", a product must have all of them.
+type SearchFilters struct {
+	Brands       []string `json:"brands,omitempty"`
+	PriceMin     *float64 `json:"price_min,omitempty"`
+	PriceMax     *float64 `json:"price_max,omitempty"`
+	Attributes   []string `json:"attributes,omitempty"`
+	MinMerchants int      `json:"min_merchants,omitempty"`
+	MaxMerchants int      `json:"max_merchants,omitempty"`
+}
+
+// SearchResponse is the result envelope of /api/search.
+// Stage is the filter relaxation stage that produced the hits.
+type SearchResponse struct {
+	Hits             []Product                   `json:"hits"`
+	Normalized       NormalizedResponse          `json:"normalized"`
+	TotalHits        int64                       `json:"total_hits"`
+	Page             int64                       `json:"page"`
+	PageSize         int64                       `json:"page_size"`
+	TotalPages       int64                       `json:"total_pages"`
+	Sort             string                      `json:"sort"`
+	Stage            string                      `json:"stage"`
+	ProcessingTimeMS int64                       `json:"processing_time_ms"`
+	Facets           map[string]map[string]int64 `json:"facets,omitempty"`
+	FacetStats       map[string]FacetStats       `json:"facet_stats,omitempty"`
+}
+
+// FacetStats contains the value range of a numeric facet
+type FacetStats struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
 }
 
 // NormalizedResponse represents the structured data returned by the AI normalizer service.
@@ -15,11 +62,183 @@ type NormalizedResponse struct {
 }
 
 // Product represents a product document in Meilisearch.
+// Products of one family share the GroupID, search hits list the family in Variants.
 type Product struct {
-	ID              string   `json:"id"`
-	Title           string   `json:"title"`
-	Category        string   `json:"category"`
-	Features        []string `json:"features"`
-	GoogleProductID string   `json:"google_product_id"`
-	ImageURL        string   `json:"image_url"`
+	ID              string          `json:"id"`
+	Title           string          `json:"title"`
+	Category        string          `json:"category"`
+	Features        []string        `json:"features"`
+	GoogleProductID string          `json:"google_product_id"`
+	ImageURL        string          `json:"image_url"`
+	ImageHash       string          `json:"image_hash,omitempty"`
+	Brand           string          `json:"brand,omitempty"`
+	GTIN            string          `json:"gtin,omitempty"`
+	PriceEUR        float64         `json:"price_eur,omitempty"`
+	MerchantCount   int             `json:"merchant_count,omitempty"`
+	GroupID         string          `json:"group_id,omitempty"`
+	Variant         *Variant        `json:"variant,omitempty"`
+	Variants        []VariantOption `json:"variants,omitempty"`
+}
+
+// Variant contains the attributes that differ between products of one family
+type Variant struct {
+	Color string `json:"color,omitempty"`
+	Size  string `json:"size,omitempty"`
+}
+
+// VariantOption is a product of a family offered in a variant picker
+type VariantOption struct {
+	ID        string  `json:"id"`
+	Title     string  `json:"title"`
+	Color     string  `json:"color,omitempty"`
+	Size      string  `json:"size,omitempty"`
+	PriceEUR  float64 `json:"price_eur,omitempty"`
+	ImageHash string  `json:"image_hash,omitempty"`
+}
+
+// Offer represents a merchant offer scraped by the parser and stored in Postgres.
+type Offer struct {
+	Merchant     string    `json:"merchant"`
+	Price        float64   `json:"price"`
+	Currency     string    `json:"currency"`
+	PriceEUR     float64   `json:"price_eur,omitempty"`
+	Link         string    `json:"link"`
+	Shipping     string    `json:"shipping,omitempty"`
+	Availability string    `json:"availability"`
+	ScrapedAt    time.Time `json:"scraped_at"`
+}
+
+// SearchSettings are the relevance settings of the products index managed by admins.
+// Synonyms maps a word to its synonyms, add both directions for mutual synonyms.
+// Empty RankingRules and a nil TypoTolerance keep the Meilisearch defaults.
+type SearchSettings struct {
+	Synonyms      map[string][]string `json:"synonyms"`
+	StopWords     []string            `json:"stop_words"`
+	TypoTolerance *TypoTolerance      `json:"typo_tolerance,omitempty"`
+	RankingRules  []string            `json:"ranking_rules"`
+}
+
+// TypoTolerance configures typo tolerance of the products index.
+// A nil Enabled keeps typo tolerance on, so a body with only word sizes does not switch it off.
+type TypoTolerance struct {
+	Enabled             *bool    `json:"enabled,omitempty"`
+	MinWordSizeOneTypo  int64    `json:"min_word_size_one_typo,omitempty"`
+	MinWordSizeTwoTypos int64    `json:"min_word_size_two_typos,omitempty"`
+	DisableOnWords      []string `json:"disable_on_words,omitempty"`
+	DisableOnAttributes []string `json:"disable_on_attributes,omitempty"`
+}
+
+// IsEnabled reports whether typo tolerance is on, true unless it is disabled explicitly
+func (t TypoTolerance) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+// Suggestion sources
+const (
+	SuggestionSourceQuery   = "query"
+	SuggestionSourceProduct = "product"
+)
+
+// Suggestion is an autocomplete entry for the search box.
+// Popularity is the number of searches for a query and the number of merchants for a product title,
+// so it is only comparable between suggestions of the same source.
+type Suggestion struct {
+	Text       string `json:"text"`
+	Source     string `json:"source"`
+	Popularity int64  `json:"popularity"`
+	ProductID  string `json:"product_id,omitempty"`
+}
+
+// SuggestResponse is the result of /api/suggest
+type SuggestResponse struct {
+	Query       string       `json:"query"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Product data sources
+const (
+	SourcePostgres    = "postgres"
+	SourceMeilisearch = "meilisearch"
+)
+
+// CategoryPathSeparator separates the levels of a category path written by the parser
+const CategoryPathSeparator = " > "
+
+// ProductDetail is the full product card of /api/product/{id}.
+// Specs are the features as name/value pairs, Images start with the main image.
+// PriceEUR is the listed price, BestPrice the cheapest in-stock offer or the listed price without offers.
+// Source is the store the card was read from, the other one only fills gaps.
+// Variants lists the product family including the product itself for a variant picker.
+type ProductDetail struct {
+	ID               string            `json:"id"`
+	Title            string            `json:"title"`
+	Description      string            `json:"description,omitempty"`
+	Brand            string            `json:"brand,omitempty"`
+	Model            string            `json:"model,omitempty"`
+	GTIN             string            `json:"gtin,omitempty"`
+	MPN              string            `json:"mpn,omitempty"`
+	Category         string            `json:"category"`
+	CategoryPath     []string          `json:"category_path"`
+	Features         []string          `json:"features"`
+	Specs            map[string]string `json:"specs"`
+	Images           []ProductImage    `json:"images"`
+	PriceEUR         float64           `json:"price_eur,omitempty"`
+	BestPrice        *BestPrice        `json:"best_price,omitempty"`
+	OfferCount       int               `json:"offer_count"`
+	URL              string            `json:"url,omitempty"`
+	GoogleProductID  string            `json:"google_product_id,omitempty"`
+	GoogleProductURL string            `json:"google_product_url,omitempty"`
+	GroupID          string            `json:"group_id,omitempty"`
+	Variant          *Variant          `json:"variant,omitempty"`
+	Variants         []VariantOption   `json:"variants,omitempty"`
+	UpdatedAt        *time.Time        `json:"updated_at,omitempty"`
+	Source           string            `json:"source"`
+	Sync             SyncStatus        `json:"sync"`
+}
+
+// ProductImage is an image of a product, Hash is set for images stored by the parser
+type ProductImage struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// BestPrice is the current best price of a product, Merchant is empty for the listed price
+type BestPrice struct {
+	PriceEUR float64 `json:"price_eur"`
+	Price    float64 `json:"price,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+	Merchant string  `json:"merchant,omitempty"`
+	Link     string  `json:"link,omitempty"`
+}
+
+// SyncStatus reports which stores contain a product and the fields they disagree on
+type SyncStatus struct {
+	Postgres    bool         `json:"postgres"`
+	Meilisearch bool         `json:"meilisearch"`
+	Drift       []DriftField `json:"drift,omitempty"`
+}
+
+// DriftField is a product field with different values in Postgres and Meilisearch
+type DriftField struct {
+	Field       string      `json:"field"`
+	Postgres    interface{} `json:"postgres"`
+	Meilisearch interface{} `json:"meilisearch"`
+}
+
+// SimilarProduct is a product related to another one.
+// Score combines semantic or keyword similarity with category and feature overlap.
+type SimilarProduct struct {
+	Product
+	Score          float64  `json:"score"`
+	SameCategory   bool     `json:"same_category"`
+	SharedFeatures []string `json:"shared_features,omitempty"`
+}
+
+// SimilarResponse is the result of /api/product/{id}/similar.
+// Method is "semantic" when the candidates came from embeddings, "keyword" otherwise.
+type SimilarResponse struct {
+	ProductID string           `json:"product_id"`
+	Cheaper   bool             `json:"cheaper"`
+	Method    string           `json:"method"`
+	Hits      []SimilarProduct `json:"hits"`
 }
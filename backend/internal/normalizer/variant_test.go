@@ -0,0 +1,73 @@
+package normalizer
+
+import (
+	"testing"
+
+	"gemini/backend/internal/models"
+)
+
+// TestApplyFamily pins group IDs computed by the parser's variant.Extract for the same products
+func TestApplyFamily(t *testing.T) {
+	tests := []struct {
+		name    string
+		product models.Product
+		groupID string
+		color   string
+		size    string
+	}{
+		{
+			name:    "colour suffix",
+			product: models.Product{Title: "New Balance Little Kid's 530 Bungee - Moonbeam/Phantom", Category: "Kids Shoes"},
+			groupID: "1d7d694f5011a9f0",
+			color:   "Moonbeam/Phantom",
+		},
+		{
+			name: "colour feature in title",
+			product: models.Product{
+				Title:    "Apple iPhone 16 Pro Max, 256GB Desert Titanium",
+				Category: "Smartphones",
+				Features: []string{"256GB", "color: Desert Titanium", "brand: Apple"},
+			},
+			groupID: "e30177bbf641bbcc",
+			color:   "Desert Titanium",
+		},
+		{
+			name: "prefixed colour feature",
+			product: models.Product{
+				Title:    "Apple iPhone 16 Pro Max, 256GB Black Titanium",
+				Category: "Smartphones",
+				Features: []string{"Design: Colour: Black Titanium", "General: Brand: Apple"},
+			},
+			groupID: "e30177bbf641bbcc",
+			color:   "Black Titanium",
+		},
+		{
+			name: "prefixed size feature",
+			product: models.Product{
+				Title:    "Samsung Crystal UHD CU7100 55\"",
+				Category: "TVs",
+				Features: []string{"Size: Screen size: 55\""},
+			},
+			groupID: "64b0146fa0b5322d",
+			size:    "55\"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			product := test.product
+			ApplyFamily(&product)
+
+			if product.GroupID != test.groupID {
+				t.Errorf("expected group %s, got %s", test.groupID, product.GroupID)
+			}
+			var color, size string
+			if product.Variant != nil {
+				color, size = product.Variant.Color, product.Variant.Size
+			}
+			if color != test.color || size != test.size {
+				t.Errorf("expected color %q size %q, got color %q size %q", test.color, test.size, color, size)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package search
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Filter is a Meilisearch filter expression built from typed conditions.
+// Values are always quoted and escaped, so text from users and the AI can't break the expression.
+// The zero Filter matches everything and is skipped by And and Or.
+type Filter struct {
+	expr string
+	// compound marks AND/OR expressions that need parentheses inside other expressions
+	compound bool
+}
+
+// Eq matches documents whose attribute equals value, for arrays any element
+func Eq(attribute, value string) Filter {
+	return Filter{expr: attribute + " = " + quote(value)}
+}
+
+// NotEq matches documents whose attribute differs from value
+func NotEq(attribute, value string) Filter {
+	return Filter{expr: attribute + " != " + quote(value)}
+}
+
+// In matches documents whose attribute equals one of the values, empty values are ignored
+func In(attribute string, values ...string) Filter {
+	var quoted []string
+	for _, value := range values {
+		if value != "" {
+			quoted = append(quoted, quote(value))
+		}
+	}
+
+	switch len(quoted) {
+	case 0:
+		return Filter{}
+	case 1:
+		return Filter{expr: attribute + " = " + quoted[0]}
+	default:
+		return Filter{expr: attribute + " IN [" + strings.Join(quoted, ", ") + "]"}
+	}
+}
+
+// Gte matches documents whose numeric attribute is at least value
+func Gte(attribute string, value float64) Filter {
+	return comparison(attribute, ">=", value)
+}
+
+// Lte matches documents whose numeric attribute is at most value
+func Lte(attribute string, value float64) Filter {
+	return comparison(attribute, "<=", value)
+}
+
+// Lt matches documents whose numeric attribute is below value
+func Lt(attribute string, value float64) Filter {
+	return comparison(attribute, "<", value)
+}
+
+// Range matches documents whose numeric attribute is between min and max inclusive.
+// A nil bound is open.
+func Range(attribute string, min, max *float64) Filter {
+	switch {
+	case min != nil && max != nil:
+		if !finite(*min) || !finite(*max) {
+			return Filter{}
+		}
+		return Filter{expr: attribute + " " + number(*min) + " TO " + number(*max)}
+	case min != nil:
+		return Gte(attribute, *min)
+	case max != nil:
+		return Lte(attribute, *max)
+	default:
+		return Filter{}
+	}
+}
+
+// Exists matches documents that have the attribute
+func Exists(attribute string) Filter {
+	return Filter{expr: attribute + " EXISTS"}
+}
+
+// Not negates a filter
+func Not(filter Filter) Filter {
+	if filter.IsEmpty() {
+		return Filter{}
+	}
+	return Filter{expr: "NOT " + filter.wrapped()}
+}
+
+// And combines filters that all must match
+func And(filters ...Filter) Filter {
+	return combine(" AND ", filters)
+}
+
+// Or combines filters of which one must match
+func Or(filters ...Filter) Filter {
+	return combine(" OR ", filters)
+}
+
+// IsEmpty reports whether the filter matches everything
+func (f Filter) IsEmpty() bool {
+	return f.expr == ""
+}
+
+// String returns the filter expression for a search request
+func (f Filter) String() string {
+	return f.expr
+}
+
+func (f Filter) wrapped() string {
+	if f.compound {
+		return "(" + f.expr + ")"
+	}
+	return f.expr
+}
+
+func combine(operator string, filters []Filter) Filter {
+	var kept []Filter
+	for _, filter := range filters {
+		if !filter.IsEmpty() {
+			kept = append(kept, filter)
+		}
+	}
+
+	switch len(kept) {
+	case 0:
+		return Filter{}
+	case 1:
+		// Единственное условие не нужно оборачивать повторно
+		return kept[0]
+	}
+
+	parts := make([]string, len(kept))
+	for i, filter := range kept {
+		parts[i] = filter.wrapped()
+	}
+	return Filter{expr: strings.Join(parts, operator), compound: true}
+}
+
+func comparison(attribute, operator string, value float64) Filter {
+	if !finite(value) {
+		return Filter{}
+	}
+	return Filter{expr: attribute + " " + operator + " " + number(value)}
+}
+
+// quote returns value as a double-quoted filter string with backslashes and quotes escaped
+func quote(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + escaped + `"`
+}
+
+func number(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func finite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
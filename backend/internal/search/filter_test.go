@@ -0,0 +1,80 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func ptr(value float64) *float64 {
+	return &value
+}
+
+func TestFilterString(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected string
+	}{
+		{"eq", Eq("brand", "Apple"), `brand = "Apple"`},
+		{"eq single quote", Eq("title", "Kid's 530"), `title = "Kid's 530"`},
+		{"eq double quote", Eq("title", `6.1" screen`), `title = "6.1\" screen"`},
+		{"eq backslash", Eq("title", `a\b`), `title = "a\\b"`},
+		{"eq injection", Eq("brand", `x" OR brand = "y`), `brand = "x\" OR brand = \"y"`},
+		{"not eq", NotEq("id", "42"), `id != "42"`},
+		{"in", In("brand", "Apple", "Samsung"), `brand IN ["Apple", "Samsung"]`},
+		{"in single value", In("brand", "", "Apple"), `brand = "Apple"`},
+		{"in empty", In("brand", "", ""), ``},
+		{"range", Range("price", ptr(100), ptr(500.5)), `price 100 TO 500.5`},
+		{"range open max", Range("price", ptr(100), nil), `price >= 100`},
+		{"range open min", Range("price", nil, ptr(500)), `price <= 500`},
+		{"range open", Range("price", nil, nil), ``},
+		{"range not finite", Range("price", ptr(math.NaN()), ptr(500)), ``},
+		{"lt", Lt("price", 9.99), `price < 9.99`},
+		{"gte infinite", Gte("price", math.Inf(1)), ``},
+		{"exists", Exists("group_id"), `group_id EXISTS`},
+		{"not", Not(Eq("brand", "Apple")), `NOT brand = "Apple"`},
+		{"not compound", Not(Or(Eq("a", "1"), Eq("b", "2"))), `NOT (a = "1" OR b = "2")`},
+		{"not empty", Not(Filter{}), ``},
+		{
+			"nested",
+			And(Eq("category", "Phones"), Or(Eq("brand", "Apple"), Eq("brand", "Samsung")), Gte("price", 100)),
+			`category = "Phones" AND (brand = "Apple" OR brand = "Samsung") AND price >= 100`,
+		},
+		{
+			"nested twice",
+			Or(And(Eq("a", "1"), Eq("b", "2")), And(Eq("c", "3"), Or(Eq("d", "4"), Eq("e", "5")))),
+			`(a = "1" AND b = "2") OR (c = "3" AND (d = "4" OR e = "5"))`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.String(); got != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	if filter := And(); !filter.IsEmpty() {
+		t.Errorf("empty And: expected empty filter, got %s", filter)
+	}
+	if filter := Or(Filter{}, Filter{}); !filter.IsEmpty() {
+		t.Errorf("Or of empty filters: expected empty filter, got %s", filter)
+	}
+
+	// Одно условие возвращается как есть и не оборачивается в скобки
+	single := And(Filter{}, Eq("brand", "Apple"), Filter{})
+	if single.String() != `brand = "Apple"` || single.compound {
+		t.Errorf("single And: expected plain condition, got %s (compound %v)", single, single.compound)
+	}
+
+	inner := Or(Eq("a", "1"), Eq("b", "2"))
+	if got := And(inner).String(); got != `a = "1" OR b = "2"` {
+		t.Errorf("single compound part: expected unwrapped expression, got %s", got)
+	}
+	if got := And(And(inner), Eq("c", "3")).String(); got != `(a = "1" OR b = "2") AND c = "3"` {
+		t.Errorf("single compound part inside And: expected parentheses, got %s", got)
+	}
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gemini/backend/internal/models"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// ErrProductNotFound is returned when the products index has no document with the ID
+var ErrProductNotFound = errors.New("product not found")
+
+// detailDocument is the product document as written by the parser
+type detailDocument struct {
+	ID              string          `json:"id"`
+	Title           string          `json:"title"`
+	Category        string          `json:"category"`
+	CategoryPath    string          `json:"category_path"`
+	Features        []string        `json:"features"`
+	ImageURL        string          `json:"image_url"`
+	ImageHash       string          `json:"image_hash"`
+	URL             string          `json:"url"`
+	Description     string          `json:"description"`
+	Brand           string          `json:"brand"`
+	Model           string          `json:"model"`
+	GTIN            string          `json:"gtin"`
+	MPN             string          `json:"mpn"`
+	PriceEUR        float64         `json:"price_eur"`
+	OfferCount      string          `json:"offer_count"`
+	MerchantCount   int             `json:"merchant_count"`
+	GoogleProductID string          `json:"google_product_id"`
+	UpdatedAt       int64           `json:"updated_at"`
+	GroupID         string          `json:"group_id"`
+	Variant         *models.Variant `json:"variant"`
+}
+
+// GetProductDetail retrieves the product card from the index, ErrProductNotFound if it doesn't exist.
+// Offers are not indexed, OfferCount is the number of merchants.
+func GetProductDetail(client meilisearch.ServiceManager, productID string) (*models.ProductDetail, error) {
+	var document detailDocument
+	err := client.Index("products").GetDocument(productID, nil, &document)
+	if err != nil {
+		var meiliErr *meilisearch.Error
+		if errors.As(err, &meiliErr) && meiliErr.StatusCode == http.StatusNotFound {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	product := &models.ProductDetail{
+		ID:              document.ID,
+		Title:           document.Title,
+		Description:     document.Description,
+		Brand:           document.Brand,
+		Model:           document.Model,
+		GTIN:            document.GTIN,
+		MPN:             document.MPN,
+		Category:        document.Category,
+		Features:        document.Features,
+		PriceEUR:        document.PriceEUR,
+		OfferCount:      document.MerchantCount,
+		URL:             document.URL,
+		GoogleProductID: document.GoogleProductID,
+		GroupID:         document.GroupID,
+		Variant:         document.Variant,
+		Source:          models.SourceMeilisearch,
+	}
+
+	if document.ImageURL != "" || document.ImageHash != "" {
+		product.Images = []models.ProductImage{{URL: document.ImageURL, Hash: document.ImageHash}}
+	}
+	if document.CategoryPath != "" {
+		product.CategoryPath = strings.Split(document.CategoryPath, models.CategoryPathSeparator)
+	}
+	if product.OfferCount == 0 {
+		product.OfferCount, _ = strconv.Atoi(strings.TrimSpace(document.OfferCount))
+	}
+	if document.UpdatedAt > 0 {
+		updatedAt := time.Unix(document.UpdatedAt, 0).UTC()
+		product.UpdatedAt = &updatedAt
+	}
+
+	return product, nil
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"slices"
+
+	"gemini/backend/internal/models"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// Relaxation stages of a search, from the strictest to the loosest
+const (
+	StageFull       = "full"
+	StageCategory   = "category"
+	StageFeatures   = "features"
+	StageText       = "text"
+	StageTitleFuzzy = "title_fuzzy"
+)
+
+// stage is one step of the relaxation ladder
+type stage struct {
+	name       string
+	filter     Filter
+	attributes []string
+	matching   meilisearch.MatchingStrategy
+}
+
+// sameAs reports whether two stages send the same search request
+func (s stage) sameAs(other stage) bool {
+	return s.filter.String() == other.filter.String() &&
+		s.matching == other.matching &&
+		slices.Equal(s.attributes, other.attributes)
+}
+
+// relaxationLadder returns the search stages for a query.
+// The AI filter is dropped step by step: full filter, category only, features only,
+// plain text with all words and finally a title search that may drop words.
+func relaxationLadder(query models.NormalizedResponse) []stage {
+	category := buildCategoryFilter(query)
+	features := buildFeaturesFilter(query)
+
+	var ladder []stage
+	// Ступени с фильтром имеют смысл только если AI что-то вернул
+	for _, filtered := range []stage{
+		{name: StageFull, filter: And(category, features)},
+		{name: StageCategory, filter: category},
+		{name: StageFeatures, filter: features},
+	} {
+		if !filtered.filter.IsEmpty() {
+			ladder = append(ladder, filtered)
+		}
+	}
+
+	return append(ladder,
+		stage{name: StageText, matching: meilisearch.All},
+		stage{name: StageTitleFuzzy, attributes: []string{"title"}, matching: meilisearch.Last},
+	)
+}
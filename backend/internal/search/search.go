@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"strings"
+	"slices"
 
 	"gemini/backend/internal/models"
 
@@ -29,8 +29,9 @@ func configureMeiliSearch(client meilisearch.ServiceManager) error {
 	// Get or create the products index
 	index := client.Index("products")
 
-	// Configure filterable attributes
-	filterableAttributes := []string{"category", "features"}
+	// Configure filterable attributes, group_id finds the variants of a family,
+	// lang and region limit suggestions to a locale
+	filterableAttributes := append(slices.Clone(FacetAttributes), "group_id", "lang", "region")
 	filterableAttrsInterface := make([]interface{}, len(filterableAttributes))
 	for i, v := range filterableAttributes {
 		filterableAttrsInterface[i] = v
@@ -49,44 +50,128 @@ func configureMeiliSearch(client meilisearch.ServiceManager) error {
 		return err
 	}
 
-	// Configure sortable attributes (optional)
-	sortableAttributes := []string{"title", "category"}
+	// Configure sortable attributes
+	sortableAttributes := SortableAttributes
 	_, err = index.UpdateSortableAttributes(&sortableAttributes)
 	if err != nil {
 		log.Printf("Error setting sortable attributes: %v", err)
 		return err
 	}
 
+	// One hit per product family, the other variants are listed in the hit
+	_, err = index.UpdateDistinctAttribute("group_id")
+	if err != nil {
+		log.Printf("Error setting distinct attribute: %v", err)
+		return err
+	}
+
 	log.Println("MeiliSearch configuration updated successfully")
 	return nil
 }
 
+// FacetAttributes are the attributes that can be filtered and faceted
+var FacetAttributes = []string{"category", "features", "brand", "price_eur", "merchant_count"}
+
+// Page sizes of search results
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 50
+)
+
+// Sort orders of search results
+const (
+	SortRelevance  = "relevance"
+	SortPriceAsc   = "price_asc"
+	SortPriceDesc  = "price_desc"
+	SortPopularity = "popularity"
+	SortNewest     = "newest"
+)
+
+// sortRules maps sort orders to Meilisearch sort rules.
+// Popularity is the number of merchants offering the product.
+var sortRules = map[string][]string{
+	SortRelevance:  nil,
+	SortPriceAsc:   {"price_eur:asc"},
+	SortPriceDesc:  {"price_eur:desc"},
+	SortPopularity: {"merchant_count:desc"},
+	SortNewest:     {"updated_at:desc"},
+}
+
+// ValidSort reports whether sort is a known sort order, empty means relevance
+func ValidSort(sort string) bool {
+	if sort == "" {
+		return true
+	}
+	_, ok := sortRules[sort]
+	return ok
+}
+
+// SortableAttributes are the attributes that can be used in sort orders and custom ranking rules
+var SortableAttributes = []string{"price_eur", "merchant_count", "updated_at"}
+
+// Options are the explicit filters, facets, page and sort order of a search.
+// Page starts at 1, PageSize is limited by MaxPageSize.
+// SemanticRatio overrides DefaultSemanticRatio when set.
+type Options struct {
+	Filters       models.SearchFilters
+	Facets        []string
+	Page          int
+	PageSize      int
+	Sort          string
+	SemanticRatio *float64
+}
+
+// paging returns the page and page size of opts with defaults and limits applied
+func (opts Options) paging() (int64, int64) {
+	page := opts.Page
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize := opts.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	return int64(page), int64(pageSize)
+}
+
 // IndexSampleProducts adds some sample products to the Meilisearch index.
 func IndexSampleProducts(client meilisearch.ServiceManager) *meilisearch.TaskInfo {
 	index := client.Index("products")
 
+	// Семья и варианты приходят с документом, их вычисляет парсер
 	documents := []models.Product{
 		{
 			ID:       "1",
 			Title:    "New Balance Little Kid's 530 Bungee - Moonbeam/Phantom",
 			Category: "Kids Shoes",
 			Features: []string{"bungee", "easy on/off", "brand: New Balance"},
+			GroupID:  "1d7d694f5011a9f0",
+			Variant:  &models.Variant{Color: "Moonbeam/Phantom"},
 		},
 		{
 			ID:       "2",
 			Title:    "New Balance Little Kid's 530 Bungee - Blue/White",
 			Category: "Kids Shoes",
 			Features: []string{"bungee", "easy on/off", "brand: New Balance"},
+			GroupID:  "1d7d694f5011a9f0",
+			Variant:  &models.Variant{Color: "Blue/White"},
 		},
 		{
 			ID:       "3",
 			Title:    "Apple iPhone 16 Pro Max, 256GB Desert Titanium",
 			Category: "Smartphones",
 			Features: []string{"256GB", "color: Desert Titanium", "brand: Apple"},
+			GroupID:  "e30177bbf641bbcc",
+			Variant:  &models.Variant{Color: "Desert Titanium"},
 		},
 	}
 
-	primaryKey := "ID"
+	primaryKey := "id"
 	task, err := index.AddDocuments(documents, &primaryKey)
 	if err != nil {
 		log.Printf("Error adding documents: %v", err)
@@ -97,66 +182,157 @@ func IndexSampleProducts(client meilisearch.ServiceManager) *meilisearch.TaskInf
 	return task
 }
 
-// buildMeiliSearchFilter creates a proper filter string for MeiliSearch
-func buildMeiliSearchFilter(query models.NormalizedResponse) string {
-	var filters []string
+// buildMeiliSearchFilter creates a proper filter for MeiliSearch
+func buildMeiliSearchFilter(query models.NormalizedResponse) Filter {
+	return And(buildCategoryFilter(query), buildFeaturesFilter(query))
+}
 
-	// Add category filter if not empty
-	if query.Category != "" {
-		filters = append(filters, fmt.Sprintf("category = '%s'", query.Category))
+// buildCategoryFilter creates the filter of the AI category
+func buildCategoryFilter(query models.NormalizedResponse) Filter {
+	if query.Category == "" {
+		return Filter{}
 	}
+	return Eq("category", query.Category)
+}
 
-	// Add features filters - use OR instead of AND for better results
-	if len(query.Features) > 0 {
-		var featureFilters []string
-		for _, feature := range query.Features {
-			if feature != "" {
-				featureFilters = append(featureFilters, fmt.Sprintf("features = '%s'", feature))
-			}
+// buildFeaturesFilter creates the filter of the AI features - use OR instead of AND for better results
+func buildFeaturesFilter(query models.NormalizedResponse) Filter {
+	var featureFilters []Filter
+	for _, feature := range query.Features {
+		if feature != "" {
+			featureFilters = append(featureFilters, Eq("features", feature))
 		}
-		if len(featureFilters) > 0 {
-			featuresFilter := strings.Join(featureFilters, " OR ")
-			if len(featureFilters) > 1 {
-				featuresFilter = "(" + featuresFilter + ")"
+	}
+	return Or(featureFilters...)
+}
+
+// buildExplicitFilter creates the filter of the filters chosen by the user
+func buildExplicitFilter(filters models.SearchFilters) Filter {
+	parts := []Filter{
+		In("brand", filters.Brands...),
+		Range("price_eur", filters.PriceMin, filters.PriceMax),
+	}
+
+	// Каждый выбранный атрибут сужает выдачу
+	for _, attribute := range filters.Attributes {
+		if attribute != "" {
+			parts = append(parts, Eq("features", attribute))
+		}
+	}
+
+	if filters.MinMerchants > 0 {
+		parts = append(parts, Gte("merchant_count", float64(filters.MinMerchants)))
+	}
+	if filters.MaxMerchants > 0 {
+		parts = append(parts, Lte("merchant_count", float64(filters.MaxMerchants)))
+	}
+
+	return And(parts...)
+}
+
+// facetNames keeps the requested facets that are filterable
+func facetNames(requested []string) []string {
+	var facets []string
+	for _, name := range requested {
+		for _, attribute := range FacetAttributes {
+			if name == attribute {
+				facets = append(facets, name)
+				break
 			}
-			filters = append(filters, featuresFilter)
 		}
 	}
+	return facets
+}
 
-	return strings.Join(filters, " AND ")
+// Result is a search response with the relaxation stage that produced it
+type Result struct {
+	*meilisearch.SearchResponse
+	Stage string
 }
 
-// Search performs a search on the products index with improved error handling.
-func Search(client meilisearch.ServiceManager, query models.NormalizedResponse) (*meilisearch.SearchResponse, error) {
+// Search performs a search on the products index, relaxing the AI filter until something is found.
+// Explicit filters of opts are applied at every stage.
+func Search(client meilisearch.ServiceManager, query models.NormalizedResponse, opts Options) (*Result, error) {
 	index := client.Index("products")
 
-	// Build MeiliSearch filter
-	filter := buildMeiliSearchFilter(query)
+	explicitFilter := buildExplicitFilter(opts.Filters)
+	facets := facetNames(opts.Facets)
+	page, pageSize := opts.paging()
 
-	searchReq := &meilisearch.SearchRequest{
-		Filter: filter,
-		Limit:  10,
+	// Вектор запроса считаем один раз для всех ступеней
+	ratio := DefaultSemanticRatio
+	if opts.SemanticRatio != nil {
+		ratio = *opts.SemanticRatio
 	}
-
-	searchRes, err := index.Search(query.Title, searchReq)
-	if err != nil {
-		log.Printf("Error searching with filter '%s': %v", filter, err)
-		// Fallback to simple text search without filters
-		searchRes, err = index.Search(query.Title, &meilisearch.SearchRequest{Limit: 10})
+	hybrid, vector := hybridSearch(query.Title, ratio)
+
+	var lastRes *Result
+	var lastErr error
+	var previous *stage
+	for _, current := range relaxationLadder(query) {
+		// Ступень без изменений относительно предыдущей даст тот же результат
+		if previous != nil && current.sameAs(*previous) {
+			continue
+		}
+		previous = &current
+
+		filter := And(current.filter, explicitFilter).String()
+		searchRes, err := index.Search(query.Title, &meilisearch.SearchRequest{
+			Filter:               filter,
+			Facets:               facets,
+			Sort:                 sortRules[opts.Sort],
+			Page:                 page,
+			HitsPerPage:          pageSize,
+			AttributesToSearchOn: current.attributes,
+			MatchingStrategy:     current.matching,
+			Hybrid:               hybrid,
+			Vector:               vector,
+		})
 		if err != nil {
-			log.Printf("Fallback search also failed: %v", err)
-			return nil, fmt.Errorf("search failed: %v", err)
+			log.Printf("Error searching at stage %s with filter '%s': %v", current.name, filter, err)
+			lastErr = err
+			continue
+		}
+
+		lastRes = &Result{SearchResponse: searchRes, Stage: current.name}
+		if searchRes.TotalHits > 0 {
+			break
+		}
+	}
+
+	if lastRes == nil {
+		return nil, fmt.Errorf("search failed: %v", lastErr)
+	}
+
+	log.Printf("Search %q answered by stage %s with %d hits", query.Title, lastRes.Stage, lastRes.TotalHits)
+	return lastRes, nil
+}
+
+// DecodeFacets converts the facet distribution and stats of a search response
+func DecodeFacets(searchRes *Result) (map[string]map[string]int64, map[string]models.FacetStats) {
+	distribution := make(map[string]map[string]int64)
+	stats := make(map[string]models.FacetStats)
+
+	if len(searchRes.FacetDistribution) > 0 {
+		if err := json.Unmarshal(searchRes.FacetDistribution, &distribution); err != nil {
+			log.Printf("Error decoding facet distribution: %v", err)
+		}
+	}
+	if len(searchRes.FacetStats) > 0 {
+		if err := json.Unmarshal(searchRes.FacetStats, &stats); err != nil {
+			log.Printf("Error decoding facet stats: %v", err)
 		}
-		log.Printf("Fallback search succeeded, returned %d results", len(searchRes.Hits))
 	}
 
-	return searchRes, nil
+	return distribution, stats
 }
 
 // AddProduct adds a product to the Meilisearch index.
 func AddProduct(client meilisearch.ServiceManager, product models.Product) (*meilisearch.TaskInfo, error) {
 	index := client.Index("products")
-	primaryKey := "ID"
+	// Варианты семьи вычисляются при поиске и не хранятся в документе
+	product.Variants = nil
+	primaryKey := "id"
 	task, err := index.AddDocuments([]models.Product{product}, &primaryKey)
 	if err != nil {
 		log.Printf("Error adding document: %v", err)
@@ -169,7 +345,8 @@ func AddProduct(client meilisearch.ServiceManager, product models.Product) (*mei
 // UpdateProduct updates a product in the Meilisearch index.
 func UpdateProduct(client meilisearch.ServiceManager, product models.Product) (*meilisearch.TaskInfo, error) {
 	index := client.Index("products")
-	primaryKey := "ID"
+	product.Variants = nil
+	primaryKey := "id"
 	task, err := index.UpdateDocuments([]models.Product{product}, &primaryKey)
 	if err != nil {
 		log.Printf("Error updating document: %v", err)
@@ -0,0 +1,195 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"gemini/backend/internal/embedding"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const (
+	// embedderName is the name of the user-provided embedder in the index settings
+	embedderName = "default"
+	// embeddingPageSize is the number of documents checked per request during a sync
+	embeddingPageSize = 1000
+	// embeddingBatchSize is the number of documents embedded and updated at once
+	embeddingBatchSize = 100
+)
+
+// DefaultSemanticRatio is the weight of semantic scores when a search does not set one:
+// 0 is keyword search only, 1 is semantic search only
+var DefaultSemanticRatio = 0.5
+
+// embedder produces document and query vectors, nil while hybrid search is disabled
+var embedder embedding.Embedder
+
+// embeddingDocument is the part of a product document used for embeddings
+type embeddingDocument struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Category      string   `json:"category"`
+	Features      []string `json:"features"`
+	Brand         string   `json:"brand,omitempty"`
+	EmbeddingHash string   `json:"embedding_hash,omitempty"`
+}
+
+// vectorDocument is a partial document updating the vector of a product
+type vectorDocument struct {
+	ID            string               `json:"id"`
+	Vectors       map[string][]float32 `json:"_vectors"`
+	EmbeddingHash string               `json:"embedding_hash"`
+}
+
+// EnableHybrid registers a user-provided embedder on the products index and turns on hybrid search
+func EnableHybrid(client meilisearch.ServiceManager, e embedding.Embedder) error {
+	index := client.Index("products")
+
+	_, err := index.UpdateEmbedders(map[string]meilisearch.Embedder{
+		embedderName: {
+			Source:     meilisearch.UserProvidedEmbedderSource,
+			Dimensions: e.Dimensions(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error setting embedder: %w", err)
+	}
+
+	embedder = e
+	log.Printf("Hybrid search enabled with embedder %s", e.Name())
+	return nil
+}
+
+// StartEmbeddingSync embeds new and changed products now and then at every interval
+func StartEmbeddingSync(client meilisearch.ServiceManager, interval time.Duration) {
+	go func() {
+		for {
+			if count, err := SyncEmbeddings(client); err != nil {
+				log.Printf("Error syncing embeddings: %v", err)
+			} else if count > 0 {
+				log.Printf("Embedded %d products", count)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// SyncEmbeddings embeds products whose text or embedder changed since their last embedding.
+// It returns the number of embedded products.
+func SyncEmbeddings(client meilisearch.ServiceManager) (int, error) {
+	if embedder == nil {
+		return 0, nil
+	}
+	index := client.Index("products")
+
+	var pending []embeddingDocument
+	for offset := int64(0); ; offset += embeddingPageSize {
+		var result meilisearch.DocumentsResult
+		err := index.GetDocuments(&meilisearch.DocumentsQuery{
+			Offset: offset,
+			Limit:  embeddingPageSize,
+			Fields: []string{"id", "title", "category", "features", "brand", "embedding_hash"},
+		}, &result)
+		if err != nil {
+			return 0, fmt.Errorf("error getting documents: %w", err)
+		}
+
+		var documents []embeddingDocument
+		if err := result.Results.Decode(&documents); err != nil {
+			return 0, fmt.Errorf("error decoding documents: %w", err)
+		}
+
+		pending = append(pending, pendingEmbeddings(documents)...)
+
+		if offset+embeddingPageSize >= result.Total {
+			break
+		}
+	}
+
+	embedded := 0
+	for start := 0; start < len(pending); start += embeddingBatchSize {
+		batch := pending[start:min(start+embeddingBatchSize, len(pending))]
+
+		texts := make([]string, len(batch))
+		for i, document := range batch {
+			texts[i] = embeddingText(document)
+		}
+		vectors, err := embedder.Embed(texts)
+		if err != nil {
+			return embedded, err
+		}
+
+		updates := make([]vectorDocument, len(batch))
+		for i, document := range batch {
+			updates[i] = vectorDocument{
+				ID:            document.ID,
+				Vectors:       map[string][]float32{embedderName: vectors[i]},
+				EmbeddingHash: embeddingHash(document),
+			}
+		}
+
+		primaryKey := "id"
+		if _, err := index.UpdateDocuments(updates, &primaryKey); err != nil {
+			return embedded, fmt.Errorf("error updating vectors: %w", err)
+		}
+		embedded += len(batch)
+	}
+
+	return embedded, nil
+}
+
+// pendingEmbeddings returns the documents whose stored hash doesn't match their current text and embedder
+func pendingEmbeddings(documents []embeddingDocument) []embeddingDocument {
+	var pending []embeddingDocument
+	for _, document := range documents {
+		if document.EmbeddingHash != embeddingHash(document) {
+			pending = append(pending, document)
+		}
+	}
+	return pending
+}
+
+// embeddingText returns the text of a product that is embedded
+func embeddingText(document embeddingDocument) string {
+	parts := []string{document.Title}
+	if document.Brand != "" {
+		parts = append(parts, document.Brand)
+	}
+	if document.Category != "" {
+		parts = append(parts, document.Category)
+	}
+	parts = append(parts, document.Features...)
+	return strings.Join(parts, ". ")
+}
+
+// embeddingHash identifies the embedded text and the embedder, a change means the vector is stale
+func embeddingHash(document embeddingDocument) string {
+	sum := sha256.Sum256([]byte(embedder.Name() + "\n" + embeddingText(document)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// hybridSearch returns the hybrid parameters and query vector of a search,
+// nil when hybrid search is disabled or the query can't be embedded.
+// The ratio is clamped to [0, 1], 0 or less means keyword search only.
+func hybridSearch(text string, ratio float64) (*meilisearch.SearchRequestHybrid, []float32) {
+	if embedder == nil || math.IsNaN(ratio) || ratio <= 0 || strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	vectors, err := embedder.Embed([]string{text})
+	if err != nil || len(vectors) == 0 {
+		log.Printf("Error embedding query %q, using keyword search: %v", text, err)
+		return nil, nil
+	}
+
+	return &meilisearch.SearchRequestHybrid{
+		SemanticRatio: min(ratio, 1),
+		Embedder:      embedderName,
+	}, vectors[0]
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"math"
+	"testing"
+
+	"gemini/backend/internal/embedding"
+)
+
+// useStubEmbedder enables the stub embedder for one test
+func useStubEmbedder(t *testing.T) {
+	t.Helper()
+	embedder = embedding.NewStub(embedding.StubDimensions)
+	t.Cleanup(func() { embedder = nil })
+}
+
+func TestPendingEmbeddings(t *testing.T) {
+	useStubEmbedder(t)
+
+	embedded := embeddingDocument{ID: "1", Title: "Apple iPhone 15 128GB", Category: "Smartphones", Brand: "Apple"}
+	embedded.EmbeddingHash = embeddingHash(embedded)
+
+	renamed := embedded
+	renamed.ID = "2"
+	renamed.Title = "Apple iPhone 15 256GB"
+
+	refeatured := embedded
+	refeatured.ID = "3"
+	refeatured.Features = []string{"colour: Black"}
+
+	fresh := embeddingDocument{ID: "4", Title: "Samsung Galaxy S24"}
+
+	pending := pendingEmbeddings([]embeddingDocument{embedded, renamed, refeatured, fresh})
+
+	var ids []string
+	for _, document := range pending {
+		ids = append(ids, document.ID)
+	}
+	if len(ids) != 3 || ids[0] != "2" || ids[1] != "3" || ids[2] != "4" {
+		t.Errorf("expected documents 2, 3 and 4 to be re-embedded, got %v", ids)
+	}
+}
+
+func TestEmbeddingHashDependsOnEmbedder(t *testing.T) {
+	document := embeddingDocument{ID: "1", Title: "Apple iPhone 15 128GB"}
+
+	embedder = embedding.NewStub(64)
+	small := embeddingHash(document)
+	useStubEmbedder(t)
+
+	if embeddingHash(document) != embeddingHash(document) {
+		t.Error("hash of the same document differs")
+	}
+	if small == embeddingHash(document) {
+		t.Error("changing the embedder should change the hash")
+	}
+}
+
+func TestHybridSearchRatio(t *testing.T) {
+	useStubEmbedder(t)
+
+	tests := []struct {
+		ratio    float64
+		expected float64
+		disabled bool
+	}{
+		{ratio: 0.3, expected: 0.3},
+		{ratio: 1, expected: 1},
+		{ratio: 2.5, expected: 1},
+		{ratio: 0, disabled: true},
+		{ratio: -1, disabled: true},
+		{ratio: math.NaN(), disabled: true},
+	}
+
+	for _, test := range tests {
+		hybrid, vector := hybridSearch("iphone 15", test.ratio)
+		if test.disabled {
+			if hybrid != nil || vector != nil {
+				t.Errorf("ratio %v: expected keyword search", test.ratio)
+			}
+			continue
+		}
+		if hybrid == nil || len(vector) != embedding.StubDimensions {
+			t.Fatalf("ratio %v: expected hybrid search with a query vector", test.ratio)
+		}
+		if hybrid.SemanticRatio != test.expected {
+			t.Errorf("ratio %v: expected %v, got %v", test.ratio, test.expected, hybrid.SemanticRatio)
+		}
+		if hybrid.Embedder != embedderName {
+			t.Errorf("ratio %v: expected embedder %s, got %s", test.ratio, embedderName, hybrid.Embedder)
+		}
+	}
+
+	if hybrid, _ := hybridSearch("   ", 0.5); hybrid != nil {
+		t.Error("blank query: expected keyword search")
+	}
+}
+
+func TestHybridSearchDisabled(t *testing.T) {
+	embedder = nil
+	if hybrid, vector := hybridSearch("iphone 15", 0.5); hybrid != nil || vector != nil {
+		t.Error("expected keyword search without an embedder")
+	}
+}
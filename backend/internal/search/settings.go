@@ -0,0 +1,123 @@
+package search
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"gemini/backend/internal/models"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// builtinRankingRules are the ranking rules of Meilisearch that take no attribute
+var builtinRankingRules = []string{"words", "typo", "proximity", "attribute", "sort", "exactness"}
+
+// ValidateSettings checks relevance settings before they are stored and applied.
+// Custom ranking rules are "attribute:asc" or "attribute:desc" on a sortable attribute,
+// the sort rule must stay so that sort orders of searches keep working.
+func ValidateSettings(settings models.SearchSettings) error {
+	for word, synonyms := range settings.Synonyms {
+		if strings.TrimSpace(word) == "" {
+			return fmt.Errorf("synonyms: empty word")
+		}
+		if len(synonyms) == 0 {
+			return fmt.Errorf("synonyms: %q has no synonyms", word)
+		}
+	}
+
+	for _, word := range settings.StopWords {
+		if strings.TrimSpace(word) == "" {
+			return fmt.Errorf("stop_words: empty word")
+		}
+	}
+
+	if typo := settings.TypoTolerance; typo != nil {
+		if typo.MinWordSizeOneTypo < 0 || typo.MinWordSizeTwoTypos < 0 {
+			return fmt.Errorf("typo_tolerance: word sizes must not be negative")
+		}
+		if typo.MinWordSizeTwoTypos > 0 && typo.MinWordSizeTwoTypos < typo.MinWordSizeOneTypo {
+			return fmt.Errorf("typo_tolerance: min_word_size_two_typos must not be smaller than min_word_size_one_typo")
+		}
+	}
+
+	if len(settings.RankingRules) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, rule := range settings.RankingRules {
+		if seen[rule] {
+			return fmt.Errorf("ranking_rules: duplicate rule %q", rule)
+		}
+		seen[rule] = true
+
+		if slices.Contains(builtinRankingRules, rule) {
+			continue
+		}
+		attribute, order, ok := strings.Cut(rule, ":")
+		if !ok || (order != "asc" && order != "desc") {
+			return fmt.Errorf("ranking_rules: %q is neither a built-in rule nor attribute:asc|desc", rule)
+		}
+		if !slices.Contains(SortableAttributes, attribute) {
+			return fmt.Errorf("ranking_rules: %q is not sortable, use one of %s", attribute, strings.Join(SortableAttributes, ", "))
+		}
+	}
+	if !seen["sort"] {
+		return fmt.Errorf("ranking_rules: the sort rule is required")
+	}
+
+	return nil
+}
+
+// ApplySettings writes relevance settings to the products index.
+// Every setting is replaced as a whole, so applying the same settings again changes nothing.
+func ApplySettings(client meilisearch.ServiceManager, settings models.SearchSettings) error {
+	index := client.Index("products")
+
+	synonyms := settings.Synonyms
+	if synonyms == nil {
+		synonyms = map[string][]string{}
+	}
+	if _, err := index.UpdateSynonyms(&synonyms); err != nil {
+		return fmt.Errorf("error setting synonyms: %w", err)
+	}
+
+	stopWords := settings.StopWords
+	if stopWords == nil {
+		stopWords = []string{}
+	}
+	if _, err := index.UpdateStopWords(&stopWords); err != nil {
+		return fmt.Errorf("error setting stop words: %w", err)
+	}
+
+	if typo := settings.TypoTolerance; typo != nil {
+		_, err := index.UpdateTypoTolerance(&meilisearch.TypoTolerance{
+			Enabled: typo.IsEnabled(),
+			MinWordSizeForTypos: meilisearch.MinWordSizeForTypos{
+				OneTypo:  typo.MinWordSizeOneTypo,
+				TwoTypos: typo.MinWordSizeTwoTypos,
+			},
+			DisableOnWords:      typo.DisableOnWords,
+			DisableOnAttributes: typo.DisableOnAttributes,
+		})
+		if err != nil {
+			return fmt.Errorf("error setting typo tolerance: %w", err)
+		}
+	} else if _, err := index.ResetTypoTolerance(); err != nil {
+		return fmt.Errorf("error resetting typo tolerance: %w", err)
+	}
+
+	if len(settings.RankingRules) > 0 {
+		rankingRules := settings.RankingRules
+		if _, err := index.UpdateRankingRules(&rankingRules); err != nil {
+			return fmt.Errorf("error setting ranking rules: %w", err)
+		}
+	} else if _, err := index.ResetRankingRules(); err != nil {
+		return fmt.Errorf("error resetting ranking rules: %w", err)
+	}
+
+	log.Printf("Search settings applied: %d synonyms, %d stop words, %d ranking rules",
+		len(synonyms), len(stopWords), len(settings.RankingRules))
+	return nil
+}
@@ -0,0 +1,172 @@
+package search
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"gemini/backend/internal/models"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// Methods used to find similar products
+const (
+	SimilarMethodSemantic = "semantic"
+	SimilarMethodKeyword  = "keyword"
+)
+
+const (
+	// similarCandidateLimit is the number of candidates re-ranked by overlap
+	similarCandidateLimit = 50
+	// Веса итоговой оценки: сходство из Meilisearch, та же категория, общие признаки
+	similarityWeight = 0.5
+	categoryWeight   = 0.3
+	featureWeight    = 0.2
+)
+
+// SimilarOptions configures a similar products lookup.
+// Cheaper keeps products of the same category priced below the product, cheapest first.
+type SimilarOptions struct {
+	Limit   int
+	Cheaper bool
+}
+
+// similarHit is a candidate with its Meilisearch ranking score
+type similarHit struct {
+	models.Product
+	RankingScore float64 `json:"_rankingScore"`
+}
+
+// Similar returns products similar to product and the method that found the candidates.
+// Candidates come from the embeddings when hybrid search is enabled and from a title search
+// otherwise, then they are ranked by similarity, category and feature overlap.
+func Similar(client meilisearch.ServiceManager, product models.ProductDetail, opts SimilarOptions) ([]models.SimilarProduct, string, error) {
+	filter, ok := similarFilter(product, opts)
+	if !ok {
+		return []models.SimilarProduct{}, "", nil
+	}
+
+	candidates, method, err := similarCandidates(client.Index("products"), product, filter.String())
+	if err != nil {
+		return nil, "", err
+	}
+
+	features := make(map[string]bool, len(product.Features))
+	for _, feature := range product.Features {
+		features[feature] = true
+	}
+
+	similar := make([]models.SimilarProduct, 0, len(candidates))
+	seen := map[string]bool{product.ID: true}
+	for _, candidate := range candidates {
+		if seen[candidate.ID] {
+			continue
+		}
+		seen[candidate.ID] = true
+
+		var shared []string
+		for _, feature := range candidate.Features {
+			if features[feature] {
+				shared = append(shared, feature)
+			}
+		}
+		sameCategory := product.Category != "" && candidate.Category == product.Category
+
+		score := similarityWeight*candidate.RankingScore + featureWeight*overlap(len(shared), len(features), len(candidate.Features))
+		if sameCategory {
+			score += categoryWeight
+		}
+
+		similar = append(similar, models.SimilarProduct{
+			Product:        candidate.Product,
+			Score:          score,
+			SameCategory:   sameCategory,
+			SharedFeatures: shared,
+		})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		if opts.Cheaper && similar[i].PriceEUR != similar[j].PriceEUR {
+			return similar[i].PriceEUR < similar[j].PriceEUR
+		}
+		return similar[i].Score > similar[j].Score
+	})
+	if len(similar) > opts.Limit {
+		similar = similar[:opts.Limit]
+	}
+
+	return similar, method, nil
+}
+
+// similarFilter returns the candidate filter excluding the product family.
+// ok is false when a cheaper lookup has nothing to compare with: no price or no category.
+func similarFilter(product models.ProductDetail, opts SimilarOptions) (filter Filter, ok bool) {
+	// Варианты того же товара (другой цвет или объем) похожими не считаются
+	if product.GroupID != "" {
+		filter = NotEq("group_id", product.GroupID)
+	}
+	if !opts.Cheaper {
+		return filter, true
+	}
+
+	price := product.PriceEUR
+	if price <= 0 && product.BestPrice != nil {
+		price = product.BestPrice.PriceEUR
+	}
+	// Без цены или категории дешевые аналоги искались бы по всему каталогу
+	if price <= 0 || product.Category == "" {
+		return Filter{}, false
+	}
+	return And(Eq("category", product.Category), Lt("price_eur", price), filter), true
+}
+
+// similarCandidates finds candidates by the product vector, by its title if that fails
+func similarCandidates(index meilisearch.IndexManager, product models.ProductDetail, filter string) ([]similarHit, string, error) {
+	if embedder != nil {
+		var result meilisearch.SimilarDocumentResult
+		err := index.SearchSimilarDocuments(&meilisearch.SimilarDocumentQuery{
+			Id:               product.ID,
+			Embedder:         embedderName,
+			Limit:            similarCandidateLimit,
+			Filter:           filter,
+			ShowRankingScore: true,
+		}, &result)
+
+		// Товар без вектора (ещё не синхронизирован) ищем по названию
+		if err != nil {
+			log.Printf("Error searching documents similar to %s, using keyword search: %v", product.ID, err)
+		} else if len(result.Hits) > 0 {
+			var hits []similarHit
+			if err := result.Hits.Decode(&hits); err != nil {
+				return nil, "", fmt.Errorf("error decoding similar documents: %w", err)
+			}
+			return hits, SimilarMethodSemantic, nil
+		}
+	}
+
+	searchRes, err := index.Search(product.Title, &meilisearch.SearchRequest{
+		Filter:           filter,
+		Limit:            similarCandidateLimit,
+		MatchingStrategy: meilisearch.Last,
+		ShowRankingScore: true,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error searching similar products: %w", err)
+	}
+
+	var hits []similarHit
+	if err := searchRes.Hits.Decode(&hits); err != nil {
+		return nil, "", fmt.Errorf("error decoding similar products: %w", err)
+	}
+	return hits, SimilarMethodKeyword, nil
+}
+
+// overlap returns the Jaccard index of two feature sets given the size of their intersection
+func overlap(shared, a, b int) float64 {
+	union := a + b - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"testing"
+
+	"gemini/backend/internal/models"
+)
+
+func TestSimilarFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		product  models.ProductDetail
+		cheaper  bool
+		expected string
+		ok       bool
+	}{
+		{"no family", models.ProductDetail{ID: "1"}, false, ``, true},
+		{"family", models.ProductDetail{ID: "1", GroupID: "apple-iphone-15"}, false, `group_id != "apple-iphone-15"`, true},
+		{
+			"cheaper",
+			models.ProductDetail{ID: "1", GroupID: "g", Category: "Phones", PriceEUR: 500},
+			true,
+			`category = "Phones" AND price_eur < 500 AND group_id != "g"`,
+			true,
+		},
+		{
+			"cheaper best price",
+			models.ProductDetail{ID: "1", Category: "Phones", BestPrice: &models.BestPrice{PriceEUR: 450}},
+			true,
+			`category = "Phones" AND price_eur < 450`,
+			true,
+		},
+		{"cheaper without price", models.ProductDetail{ID: "1", Category: "Phones"}, true, ``, false},
+		{"cheaper without category", models.ProductDetail{ID: "1", PriceEUR: 500}, true, ``, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, ok := similarFilter(test.product, SimilarOptions{Cheaper: test.cheaper})
+			if ok != test.ok {
+				t.Fatalf("expected ok %v, got %v", test.ok, ok)
+			}
+			if got := filter.String(); got != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
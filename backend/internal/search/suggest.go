@@ -0,0 +1,115 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gemini/backend/internal/models"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// suggestionDocument is the part of a product document used for suggestions
+type suggestionDocument struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	MerchantCount int64  `json:"merchant_count"`
+}
+
+// SuggestTitles returns product titles matching prefix, the last word may be incomplete.
+// Products sold by more merchants come first among equally relevant titles.
+// An empty lang or region matches any, products without them match every language and region.
+func SuggestTitles(client meilisearch.ServiceManager, prefix, lang, region string, limit int) ([]models.Suggestion, error) {
+	filter := And(localeFilter("lang", lang), localeFilter("region", region))
+
+	searchRes, err := client.Index("products").Search(prefix, &meilisearch.SearchRequest{
+		Limit:                int64(limit),
+		AttributesToSearchOn: []string{"title"},
+		AttributesToRetrieve: []string{"id", "title", "merchant_count"},
+		MatchingStrategy:     meilisearch.All,
+		Sort:                 sortRules[SortPopularity],
+		Filter:               filter.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error searching titles: %w", err)
+	}
+
+	var documents []suggestionDocument
+	if err := searchRes.Hits.Decode(&documents); err != nil {
+		return nil, fmt.Errorf("error decoding titles: %w", err)
+	}
+
+	suggestions := make([]models.Suggestion, 0, len(documents))
+	for _, document := range documents {
+		suggestions = append(suggestions, models.Suggestion{
+			Text:       document.Title,
+			Source:     models.SuggestionSourceProduct,
+			Popularity: document.MerchantCount,
+			ProductID:  document.ID,
+		})
+	}
+	return suggestions, nil
+}
+
+// localeFilter matches documents with the given value of a locale attribute or without the attribute
+func localeFilter(attribute, value string) Filter {
+	if value == "" {
+		return Filter{}
+	}
+	return Or(Not(Exists(attribute)), Eq(attribute, value))
+}
+
+// MergeSuggestions deduplicates suggestions case-insensitively and ranks them by popularity.
+// Each list is one source, its popularity is scaled by the most popular entry of the list,
+// so search counts and merchant counts compete on the same 0-1 scale.
+// Of duplicates the higher scored one is kept, past queries win ties against product titles.
+func MergeSuggestions(limit int, lists ...[]models.Suggestion) []models.Suggestion {
+	type scored struct {
+		suggestion models.Suggestion
+		score      float64
+	}
+
+	var merged []scored
+	positions := make(map[string]int)
+	for _, list := range lists {
+		var top int64
+		for _, suggestion := range list {
+			top = max(top, suggestion.Popularity)
+		}
+
+		for _, suggestion := range list {
+			key := strings.Join(strings.Fields(strings.ToLower(suggestion.Text)), " ")
+			if key == "" {
+				continue
+			}
+
+			entry := scored{suggestion: suggestion}
+			if top > 0 {
+				entry.score = float64(suggestion.Popularity) / float64(top)
+			}
+
+			if i, ok := positions[key]; ok {
+				if entry.score > merged[i].score {
+					merged[i] = entry
+				}
+				continue
+			}
+			positions[key] = len(merged)
+			merged = append(merged, entry)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].score != merged[j].score {
+			return merged[i].score > merged[j].score
+		}
+		return merged[i].suggestion.Source == models.SuggestionSourceQuery && merged[j].suggestion.Source != models.SuggestionSourceQuery
+	})
+
+	suggestions := make([]models.Suggestion, 0, min(len(merged), limit))
+	for _, entry := range merged[:min(len(merged), limit)] {
+		suggestions = append(suggestions, entry.suggestion)
+	}
+	return suggestions
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"testing"
+
+	"gemini/backend/internal/models"
+)
+
+func TestMergeSuggestions(t *testing.T) {
+	queries := []models.Suggestion{
+		{Text: "iphone 15", Source: models.SuggestionSourceQuery, Popularity: 1000},
+		{Text: "iphone 15 case", Source: models.SuggestionSourceQuery, Popularity: 100},
+	}
+	titles := []models.Suggestion{
+		{Text: "Apple iPhone 15 128GB", Source: models.SuggestionSourceProduct, Popularity: 40, ProductID: "1"},
+		{Text: "Apple iPhone 15 Pro", Source: models.SuggestionSourceProduct, Popularity: 20, ProductID: "2"},
+		{Text: "iPhone  15", Source: models.SuggestionSourceProduct, Popularity: 40, ProductID: "3"},
+	}
+
+	merged := MergeSuggestions(10, queries, titles)
+
+	// Счетчики источников в разных масштабах: 20 магазинов выше 100 поисков из 1000
+	expected := []string{"iphone 15", "Apple iPhone 15 128GB", "Apple iPhone 15 Pro", "iphone 15 case"}
+	if len(merged) != len(expected) {
+		t.Fatalf("expected %d suggestions, got %d: %v", len(expected), len(merged), merged)
+	}
+	for i, text := range expected {
+		if merged[i].Text != text {
+			t.Errorf("%d: expected %q, got %q", i, text, merged[i].Text)
+		}
+	}
+	if merged[0].Source != models.SuggestionSourceQuery || merged[0].Popularity != 1000 {
+		t.Errorf("duplicate tie: expected the query to be kept, got %+v", merged[0])
+	}
+
+	if limited := MergeSuggestions(2, queries, titles); len(limited) != 2 {
+		t.Errorf("expected 2 suggestions, got %d", len(limited))
+	}
+	if empty := MergeSuggestions(5); empty == nil || len(empty) != 0 {
+		t.Errorf("expected an empty non-nil list, got %v", empty)
+	}
+}
+
+func TestMergeSuggestionsWithoutPopularity(t *testing.T) {
+	titles := []models.Suggestion{
+		{Text: "Apple iPhone 15", Source: models.SuggestionSourceProduct},
+		{Text: "Apple iPhone 14", Source: models.SuggestionSourceProduct},
+	}
+	queries := []models.Suggestion{{Text: "iphone", Source: models.SuggestionSourceQuery, Popularity: 3}}
+
+	merged := MergeSuggestions(10, queries, titles)
+	if len(merged) != 3 || merged[0].Text != "iphone" || merged[1].Text != "Apple iPhone 15" {
+		t.Errorf("unexpected order: %v", merged)
+	}
+}
+
+func TestLocaleFilter(t *testing.T) {
+	if filter := localeFilter("region", ""); !filter.IsEmpty() {
+		t.Errorf("empty region: expected no filter, got %s", filter)
+	}
+
+	got := And(localeFilter("lang", "en"), localeFilter("region", "gb")).String()
+	expected := `(NOT lang EXISTS OR lang = "en") AND (NOT region EXISTS OR region = "gb")`
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+
+	"gemini/backend/internal/models"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// familyLimit is the maximum number of variant documents fetched at once
+const familyLimit = 1000
+
+// variantDocument is the part of a product document shown in a variant picker
+type variantDocument struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	GroupID   string          `json:"group_id"`
+	Variant   *models.Variant `json:"variant"`
+	PriceEUR  float64         `json:"price_eur"`
+	ImageHash string          `json:"image_hash"`
+}
+
+// AttachVariants lists the family of every product that has siblings in its Variants
+func AttachVariants(client meilisearch.ServiceManager, products []models.Product) error {
+	var groupIDs []string
+	for _, product := range products {
+		if product.GroupID != "" {
+			groupIDs = append(groupIDs, product.GroupID)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	families, err := familyVariants(client, groupIDs)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		if family := families[products[i].GroupID]; len(family) > 1 {
+			products[i].Variants = family
+		}
+	}
+	return nil
+}
+
+// FamilyVariants returns all products of a family, nil if the product has no siblings
+func FamilyVariants(client meilisearch.ServiceManager, groupID string) ([]models.VariantOption, error) {
+	if groupID == "" {
+		return nil, nil
+	}
+	families, err := familyVariants(client, []string{groupID})
+	if err != nil {
+		return nil, err
+	}
+	if family := families[groupID]; len(family) > 1 {
+		return family, nil
+	}
+	return nil, nil
+}
+
+// familyVariants fetches the documents of the groups, documents are not collapsed by distinct
+func familyVariants(client meilisearch.ServiceManager, groupIDs []string) (map[string][]models.VariantOption, error) {
+	var result meilisearch.DocumentsResult
+	err := client.Index("products").GetDocuments(&meilisearch.DocumentsQuery{
+		Limit:  familyLimit,
+		Fields: []string{"id", "title", "group_id", "variant", "price_eur", "image_hash"},
+		Filter: In("group_id", groupIDs...).String(),
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("error getting variants: %w", err)
+	}
+
+	var documents []variantDocument
+	if err := result.Results.Decode(&documents); err != nil {
+		return nil, fmt.Errorf("error decoding variants: %w", err)
+	}
+
+	families := make(map[string][]models.VariantOption)
+	for _, document := range documents {
+		option := models.VariantOption{
+			ID:        document.ID,
+			Title:     document.Title,
+			PriceEUR:  document.PriceEUR,
+			ImageHash: document.ImageHash,
+		}
+		if document.Variant != nil {
+			option.Color = document.Variant.Color
+			option.Size = document.Variant.Size
+		}
+		families[document.GroupID] = append(families[document.GroupID], option)
+	}
+
+	for _, family := range families {
+		sort.Slice(family, func(i, j int) bool {
+			if family[i].Color != family[j].Color {
+				return family[i].Color < family[j].Color
+			}
+			if family[i].Size != family[j].Size {
+				return family[i].Size < family[j].Size
+			}
+			return family[i].ID < family[j].ID
+		})
+	}
+	return families, nil
+}
@@ -2,7 +2,10 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"gemini/backend/internal/models"
 
@@ -21,24 +24,81 @@ func NewDB(dataSourceName string) (*sql.DB, error) {
 	return db, nil
 }
 
+// EnsureSchema creates the tables the backend writes itself, for databases set up before init.sql had them.
+// It is run once at startup.
+func EnsureSchema(db *sql.DB) error {
+	if err := ensureSearchLogsTable(db); err != nil {
+		return err
+	}
+	return ensureSearchSettingsTable(db)
+}
+
 // AddProduct adds a new product to the database.
 func AddProduct(db *sql.DB, product models.Product) error {
-	query := `INSERT INTO products (id, title, category, features, google_product_id, image_url) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := db.Exec(query, product.ID, product.Title, product.Category, pq.Array(product.Features), product.GoogleProductID, product.ImageURL)
+	query := `INSERT INTO products (id, title, category, features, google_product_id, image_url, group_id, variant) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := db.Exec(query, product.ID, product.Title, product.Category, pq.Array(product.Features), product.GoogleProductID, product.ImageURL, product.GroupID, variantJSON(product.Variant))
 	return err
 }
 
-// GetProduct retrieves a product from the database by its ID.
-func GetProduct(db *sql.DB, productID string) (*models.Product, error) {
-	query := `SELECT id, title, category, features, google_product_id, image_url FROM products WHERE id = $1`
+// GetProduct retrieves the product card stored by the parser, sql.ErrNoRows if it doesn't exist.
+// Offers are not included, see GetOffers.
+func GetProduct(db *sql.DB, productID string) (*models.ProductDetail, error) {
+	query := `
+		SELECT id, title, COALESCE(url, ''), COALESCE(image_url, ''), COALESCE(image_hash, ''),
+		       COALESCE(price_eur, 0), COALESCE(offer_count, ''), features, COALESCE(category, ''),
+		       COALESCE(category_path, ''), additional_images, COALESCE(google_product_id, ''),
+		       COALESCE(description, ''), COALESCE(brand, ''), COALESCE(model, ''),
+		       COALESCE(gtin, ''), COALESCE(mpn, ''), updated_at, COALESCE(group_id, ''), variant
+		FROM products
+		WHERE id = $1
+	`
 	row := db.QueryRow(query, productID)
 
-	var product models.Product
-	var features pq.StringArray
-	if err := row.Scan(&product.ID, &product.Title, &product.Category, &features, &product.GoogleProductID, &product.ImageURL); err != nil {
+	product := models.ProductDetail{Source: models.SourcePostgres}
+	var imageURL, imageHash, offerCount, categoryPath string
+	var features, additionalImages, variant []byte
+	var updatedAt sql.NullTime
+	err := row.Scan(&product.ID, &product.Title, &product.URL, &imageURL, &imageHash,
+		&product.PriceEUR, &offerCount, &features, &product.Category,
+		&categoryPath, &additionalImages, &product.GoogleProductID,
+		&product.Description, &product.Brand, &product.Model,
+		&product.GTIN, &product.MPN, &updatedAt, &product.GroupID, &variant)
+	if err != nil {
 		return nil, err
 	}
-	product.Features = []string(features)
+
+	// Парсер хранит признаки объектом "name": "value"
+	if len(features) > 0 {
+		if err := json.Unmarshal(features, &product.Specs); err != nil {
+			return nil, fmt.Errorf("error decoding features of product %s: %v", productID, err)
+		}
+	}
+
+	if imageURL != "" || imageHash != "" {
+		product.Images = append(product.Images, models.ProductImage{URL: imageURL, Hash: imageHash})
+	}
+	if len(additionalImages) > 0 {
+		var extra []models.ProductImage
+		if err := json.Unmarshal(additionalImages, &extra); err != nil {
+			return nil, fmt.Errorf("error decoding images of product %s: %v", productID, err)
+		}
+		product.Images = append(product.Images, extra...)
+	}
+
+	// JSONB null означает товар без вариантов
+	if len(variant) > 0 {
+		if err := json.Unmarshal(variant, &product.Variant); err != nil {
+			return nil, fmt.Errorf("error decoding variant of product %s: %v", productID, err)
+		}
+	}
+
+	if categoryPath != "" {
+		product.CategoryPath = strings.Split(categoryPath, models.CategoryPathSeparator)
+	}
+	product.OfferCount, _ = strconv.Atoi(strings.TrimSpace(offerCount))
+	if updatedAt.Valid {
+		product.UpdatedAt = &updatedAt.Time
+	}
 
 	return &product, nil
 }
@@ -115,11 +175,20 @@ func GetAllProducts(db *sql.DB) ([]models.Product, error) {
 
 // UpdateProduct updates a product in the database.
 func UpdateProduct(db *sql.DB, product models.Product) error {
-	query := `UPDATE products SET title = $2, category = $3, features = $4, google_product_id = $5, image_url = $6 WHERE id = $1`
-	_, err := db.Exec(query, product.ID, product.Title, product.Category, pq.Array(product.Features), product.GoogleProductID, product.ImageURL)
+	query := `UPDATE products SET title = $2, category = $3, features = $4, google_product_id = $5, image_url = $6, group_id = $7, variant = $8 WHERE id = $1`
+	_, err := db.Exec(query, product.ID, product.Title, product.Category, pq.Array(product.Features), product.GoogleProductID, product.ImageURL, product.GroupID, variantJSON(product.Variant))
 	return err
 }
 
+// variantJSON encodes variant attributes for the JSONB column, NULL without a variant
+func variantJSON(variant *models.Variant) interface{} {
+	if variant == nil {
+		return nil
+	}
+	data, _ := json.Marshal(variant)
+	return data
+}
+
 // DeleteProduct deletes a product from the database.
 func DeleteProduct(db *sql.DB, productID string) error {
 	query := `DELETE FROM products WHERE id = $1`
@@ -127,44 +196,104 @@ func DeleteProduct(db *sql.DB, productID string) error {
 	return err
 }
 
-// LogSearchQuery logs a search query to the database with proper error handling.
-func LogSearchQuery(db *sql.DB, query string, normalizedTitle string, category string) error {
-	// Check if search_logs table exists first
-	var exists bool
-	err := db.QueryRow(`
-		SELECT EXISTS (
-			SELECT FROM information_schema.tables 
-			WHERE table_schema = 'public' 
-			AND table_name = 'search_logs'
+// GetOffers retrieves the merchant offers scraped for a product, cheapest first.
+func GetOffers(db *sql.DB, productID string) ([]models.Offer, error) {
+	query := `
+		SELECT merchant, COALESCE(price, 0), COALESCE(currency, ''), COALESCE(price_eur, 0),
+		       COALESCE(url, ''), COALESCE(shipping, ''), COALESCE(in_stock, TRUE), scraped_at
+		FROM offers
+		WHERE product_id = $1
+		ORDER BY price_eur NULLS LAST, price
+	`
+	rows, err := db.Query(query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying offers: %v", err)
+	}
+	defer rows.Close()
+
+	var offers []models.Offer
+	for rows.Next() {
+		var offer models.Offer
+		var inStock bool
+		if err := rows.Scan(&offer.Merchant, &offer.Price, &offer.Currency, &offer.PriceEUR,
+			&offer.Link, &offer.Shipping, &inStock, &offer.ScrapedAt); err != nil {
+			continue // Skip problematic rows
+		}
+
+		offer.Availability = "In stock"
+		if !inStock {
+			offer.Availability = "Out of stock"
+		}
+		offers = append(offers, offer)
+	}
+
+	return offers, rows.Err()
+}
+
+// ensureSearchLogsTable creates the search_logs table and adds columns missing in older tables
+func ensureSearchLogsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS search_logs (
+			id SERIAL PRIMARY KEY,
+			query TEXT NOT NULL,
+			normalized_title TEXT,
+			category TEXT,
+			lang TEXT,
+			region TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
-	`).Scan(&exists)
+		ALTER TABLE search_logs ADD COLUMN IF NOT EXISTS lang TEXT;
+		ALTER TABLE search_logs ADD COLUMN IF NOT EXISTS region TEXT;
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating search_logs table: %v", err)
+	}
+	return nil
+}
+
+// LogSearchQuery logs a search query with its language and region to the database.
+func LogSearchQuery(db *sql.DB, query, normalizedTitle, category, lang, region string) error {
+	insertQuery := `INSERT INTO search_logs (query, normalized_title, category, lang, region) VALUES ($1, $2, $3, $4, $5)`
+	_, err := db.Exec(insertQuery, query, normalizedTitle, category, lang, region)
+	return err
+}
 
+// GetPopularQueries retrieves logged queries starting with prefix, most searched first.
+// Queries are compared case-insensitively. An empty lang or region matches any,
+// queries logged without them match every language and region.
+func GetPopularQueries(db *sql.DB, prefix, lang, region string, limit int) ([]models.Suggestion, error) {
+	query := `
+		SELECT MIN(TRIM(query)), COUNT(*) AS searches
+		FROM search_logs
+		WHERE lower(query) LIKE $1 ESCAPE '\'
+		  AND ($2 = '' OR COALESCE(lang, '') IN ('', $2))
+		  AND ($3 = '' OR COALESCE(region, '') IN ('', $3))
+		GROUP BY lower(TRIM(query))
+		ORDER BY searches DESC, MIN(TRIM(query))
+		LIMIT $4
+	`
+	pattern := likeEscaper.Replace(strings.ToLower(strings.TrimSpace(prefix))) + "%"
+	rows, err := db.Query(query, pattern, lang, region, limit)
 	if err != nil {
-		return fmt.Errorf("error checking if search_logs table exists: %v", err)
+		return nil, fmt.Errorf("error querying popular queries: %v", err)
 	}
+	defer rows.Close()
 
-	if !exists {
-		// Create the table if it doesn't exist
-		createTableQuery := `
-			CREATE TABLE search_logs (
-				id SERIAL PRIMARY KEY,
-				query TEXT NOT NULL,
-				normalized_title TEXT,
-				category TEXT,
-				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-			);
-		`
-		_, err = db.Exec(createTableQuery)
-		if err != nil {
-			return fmt.Errorf("error creating search_logs table: %v", err)
+	var suggestions []models.Suggestion
+	for rows.Next() {
+		suggestion := models.Suggestion{Source: models.SuggestionSourceQuery}
+		if err := rows.Scan(&suggestion.Text, &suggestion.Popularity); err != nil {
+			continue // Skip problematic rows
 		}
+		suggestions = append(suggestions, suggestion)
 	}
 
-	insertQuery := `INSERT INTO search_logs (query, normalized_title, category) VALUES ($1, $2, $3)`
-	_, err = db.Exec(insertQuery, query, normalizedTitle, category)
-	return err
+	return suggestions, rows.Err()
 }
 
+// likeEscaper escapes LIKE wildcards so user input matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // GetSearchStatistics retrieves search statistics from the database with proper error handling.
 func GetSearchStatistics(db *sql.DB) (map[string]interface{}, error) {
 	// Check if search_logs table exists
@@ -7,9 +7,15 @@ CREATE TABLE IF NOT EXISTS search_logs (
     query TEXT NOT NULL,
     normalized_title TEXT,
     category TEXT,
+    lang TEXT,
+    region TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Language and region of queries logged before they were recorded stay empty
+ALTER TABLE search_logs ADD COLUMN IF NOT EXISTS lang TEXT;
+ALTER TABLE search_logs ADD COLUMN IF NOT EXISTS region TEXT;
+
 -- Create products table with correct schema if it doesn't exist
 CREATE TABLE IF NOT EXISTS products (
     id VARCHAR(50) PRIMARY KEY,
@@ -28,11 +34,46 @@ CREATE TABLE IF NOT EXISTS products (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Columns added by the parser, the product card reads them
+ALTER TABLE products ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS brand TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS model TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS gtin VARCHAR(14);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS mpn TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_path TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS image_hash VARCHAR(64);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS group_id VARCHAR(32);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS variant JSONB;
+
+-- Merchant offers scraped by the parser
+CREATE TABLE IF NOT EXISTS offers (
+    id SERIAL PRIMARY KEY,
+    product_id VARCHAR(50) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    merchant TEXT NOT NULL,
+    price DECIMAL(10,2),
+    currency VARCHAR(3),
+    price_eur DECIMAL(10,2),
+    shipping TEXT,
+    in_stock BOOLEAN DEFAULT TRUE,
+    url TEXT,
+    scraped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Relevance settings of Meilisearch indexes managed through the admin API
+CREATE TABLE IF NOT EXISTS search_settings (
+    index_uid TEXT PRIMARY KEY,
+    settings JSONB NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- If you have existing products table with 'categories' column, rename it
 -- ALTER TABLE products RENAME COLUMN categories TO category;
 
 -- Add indexes for better performance
 CREATE INDEX IF NOT EXISTS idx_products_category ON products(category);
 CREATE INDEX IF NOT EXISTS idx_products_title ON products(title);
+CREATE INDEX IF NOT EXISTS idx_products_group_id ON products(group_id);
+CREATE INDEX IF NOT EXISTS idx_offers_product_id ON offers(product_id);
 CREATE INDEX IF NOT EXISTS idx_search_logs_query ON search_logs(query);
-CREATE INDEX IF NOT EXISTS idx_search_logs_category ON search_logs(category);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_search_logs_category ON search_logs(category);
+CREATE INDEX IF NOT EXISTS idx_search_logs_query_prefix ON search_logs(lower(query) text_pattern_ops);
\ No newline at end of file
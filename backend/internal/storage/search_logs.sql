@@ -3,5 +3,7 @@ CREATE TABLE IF NOT EXISTS search_logs (
     query TEXT NOT NULL,
     normalized_title TEXT,
     category TEXT,
+    lang TEXT,
+    region TEXT,
     timestamp TIMESTAMPTZ DEFAULT NOW()
 );
\ No newline at end of file
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"gemini/backend/internal/models"
+)
+
+// searchSettingsIndex is the index whose settings are stored
+const searchSettingsIndex = "products"
+
+// ensureSearchSettingsTable creates the search_settings table if it doesn't exist
+func ensureSearchSettingsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS search_settings (
+			index_uid TEXT PRIMARY KEY,
+			settings JSONB NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating search_settings table: %v", err)
+	}
+	return nil
+}
+
+// GetSearchSettings retrieves the stored relevance settings, nil if none were saved yet.
+func GetSearchSettings(db *sql.DB) (*models.SearchSettings, error) {
+	var data []byte
+	err := db.QueryRow(`SELECT settings FROM search_settings WHERE index_uid = $1`, searchSettingsIndex).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying search settings: %v", err)
+	}
+
+	var settings models.SearchSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("error decoding search settings: %v", err)
+	}
+	return &settings, nil
+}
+
+// SaveSearchSettings stores the relevance settings, replacing the previous ones.
+func SaveSearchSettings(db *sql.DB, settings models.SearchSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("error encoding search settings: %v", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO search_settings (index_uid, settings, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (index_uid) DO UPDATE SET settings = EXCLUDED.settings, updated_at = EXCLUDED.updated_at
+	`, searchSettingsIndex, data)
+	if err != nil {
+		return fmt.Errorf("error saving search settings: %v", err)
+	}
+	return nil
+}
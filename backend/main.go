@@ -4,16 +4,23 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
 	"gemini/backend/internal/cache"
+	"gemini/backend/internal/catalog"
+	"gemini/backend/internal/embedding"
+	"gemini/backend/internal/images"
 	"gemini/backend/internal/models"
 	"gemini/backend/internal/normalizer"
 	"gemini/backend/internal/search"
@@ -27,6 +34,7 @@ import (
 var (
 	meiliClient meilisearch.ServiceManager
 	db          *sql.DB
+	imageStore  images.Store
 )
 
 func withCORS(fn http.HandlerFunc) http.HandlerFunc {
@@ -58,21 +66,41 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Received search query: %+v\n", req)
 
-	normalizedResp, err := normalizer.Normalize(req)
-	if err != nil {
-		log.Printf("Error normalizing query: %v", err)
-		http.Error(w, "Error normalizing query: "+err.Error(), http.StatusInternalServerError)
+	if !search.ValidSort(req.Sort) {
+		http.Error(w, "Unknown sort order: "+req.Sort, http.StatusBadRequest)
 		return
 	}
+	if req.Sort == "" {
+		req.Sort = search.SortRelevance
+	}
 
-	log.Printf("Normalized response: %+v\n", normalizedResp)
+	// Уточнение фильтрами переиспользует нормализованный запрос без вызова AI
+	normalizedResp := req.Normalized
+	if normalizedResp == nil {
+		var err error
+		normalizedResp, err = normalizer.Normalize(req)
+		if err != nil {
+			log.Printf("Error normalizing query: %v", err)
+			http.Error(w, "Error normalizing query: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Normalized response: %+v\n", normalizedResp)
 
-	// Log search query (don't fail the request if logging fails)
-	if err := storage.LogSearchQuery(db, req.Query, normalizedResp.Title, normalizedResp.Category); err != nil {
-		log.Printf("Error logging search query: %v", err)
+		// Log search query (don't fail the request if logging fails)
+		if err := storage.LogSearchQuery(db, req.Query, normalizedResp.Title, normalizedResp.Category, req.Lang, req.Region); err != nil {
+			log.Printf("Error logging search query: %v", err)
+		}
 	}
 
-	searchResults, err := search.Search(meiliClient, *normalizedResp)
+	searchResults, err := search.Search(meiliClient, *normalizedResp, search.Options{
+		Filters:       req.Filters,
+		Facets:        req.Facets,
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+		Sort:          req.Sort,
+		SemanticRatio: req.SemanticRatio,
+	})
 	if err != nil {
 		log.Printf("Error searching: %v", err)
 		http.Error(w, "Error searching: "+err.Error(), http.StatusInternalServerError)
@@ -86,7 +114,12 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 	for i := range products {
 		if products[i].GoogleProductID == "" {
 			log.Printf("Product %s does not have a Google Product ID. Calling SerpApi.", products[i].Title)
-			googleProductID, err := serpapi.SearchProduct(products[i].Title)
+			// GTIN identifies the product exactly, the title is only a fallback
+			lookupQuery := products[i].Title
+			if products[i].GTIN != "" {
+				lookupQuery = products[i].GTIN
+			}
+			googleProductID, err := serpapi.SearchProduct(lookupQuery)
 			if err != nil {
 				log.Printf("Error searching for product %s on SerpApi: %v", products[i].Title, err)
 			} else {
@@ -102,13 +135,192 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Варианты добавляются после обновления документов, в индексе их нет
+	if err := search.AttachVariants(meiliClient, products); err != nil {
+		log.Printf("Error attaching variants: %v", err)
+	}
+
+	if products == nil {
+		products = []models.Product{}
+	}
+
+	response := models.SearchResponse{
+		Hits:             products,
+		Normalized:       *normalizedResp,
+		TotalHits:        searchResults.TotalHits,
+		Page:             searchResults.Page,
+		PageSize:         searchResults.HitsPerPage,
+		TotalPages:       searchResults.TotalPages,
+		Sort:             req.Sort,
+		Stage:            searchResults.Stage,
+		ProcessingTimeMS: searchResults.ProcessingTimeMs,
+	}
+	if len(req.Facets) > 0 {
+		response.Facets, response.FacetStats = search.DecodeFacets(searchResults)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+const (
+	// defaultSuggestions is the number of suggestions when the request sets no limit
+	defaultSuggestions = 8
+	// maxSuggestions is the largest accepted suggestion limit
+	maxSuggestions = 20
+	// defaultSimilarProducts is the number of similar products when the request sets no limit
+	defaultSimilarProducts = 8
+	// maxSimilarProducts is the largest accepted similar products limit
+	maxSimilarProducts = 20
+)
+
+// suggestHandler returns autocomplete suggestions for GET /api/suggest?q=&lang=&region=&limit=
+// from product titles and popular past queries. It never calls the AI normalizer.
+func suggestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	prefix := strings.TrimSpace(params.Get("q"))
+	lang := params.Get("lang")
+	region := params.Get("region")
+
+	limit := defaultSuggestions
+	if value := params.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit: "+value, http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSuggestions)
+	}
+
+	response := models.SuggestResponse{Query: prefix, Suggestions: []models.Suggestion{}}
+	if prefix == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	cacheKey := fmt.Sprintf("suggest:%s:%s:%d:%s", lang, region, limit, strings.ToLower(prefix))
+	if err := cache.Get(cacheKey, &response.Suggestions); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// Источники независимы: отказ одного не ломает подсказки другого
+	queries, err := storage.GetPopularQueries(db, prefix, lang, region, limit)
+	if err != nil {
+		log.Printf("Error getting popular queries for %q: %v", prefix, err)
+	}
+	titles, err := search.SuggestTitles(meiliClient, prefix, lang, region, limit)
+	if err != nil {
+		log.Printf("Error getting title suggestions for %q: %v", prefix, err)
+	}
+
+	response.Suggestions = search.MergeSuggestions(limit, queries, titles)
+	cache.Set(cacheKey, response.Suggestions, 5*time.Minute)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	json.NewEncoder(w).Encode(response)
 }
 
-func productOffersHandler(w http.ResponseWriter, r *http.Request) {
-	productID := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	productID = strings.TrimSuffix(productID, "/offers")
+// productHandler routes /api/product/{id} to the product card, /api/product/{id}/offers to its offers
+// and /api/product/{id}/similar to similar products
+func productHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	productID, action, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/product/"), "/"), "/")
+	if productID == "" {
+		http.Error(w, "Product ID is required", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "":
+		productDetailHandler(w, productID)
+	case "offers":
+		productOffersHandler(w, productID)
+	case "similar":
+		productSimilarHandler(w, r, productID)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// productDetailHandler returns the full product card merged from Postgres and Meilisearch
+func productDetailHandler(w http.ResponseWriter, productID string) {
+	product, err := catalog.GetProduct(db, meiliClient, productID)
+	if errors.Is(err, catalog.ErrNotFound) {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting product %s: %v", productID, err)
+		http.Error(w, "Error getting product", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// productSimilarHandler returns similar products, with ?cheaper=true cheaper alternatives of the same category
+func productSimilarHandler(w http.ResponseWriter, r *http.Request, productID string) {
+	params := r.URL.Query()
+
+	opts := search.SimilarOptions{Limit: defaultSimilarProducts}
+	if value := params.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit: "+value, http.StatusBadRequest)
+			return
+		}
+		opts.Limit = min(n, maxSimilarProducts)
+	}
+	if value := params.Get("cheaper"); value != "" {
+		cheaper, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid cheaper: "+value, http.StatusBadRequest)
+			return
+		}
+		opts.Cheaper = cheaper
+	}
+
+	product, err := catalog.GetProduct(db, meiliClient, productID)
+	if errors.Is(err, catalog.ErrNotFound) {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting product %s: %v", productID, err)
+		http.Error(w, "Error getting product", http.StatusInternalServerError)
+		return
+	}
+
+	similar, method, err := search.Similar(meiliClient, *product, opts)
+	if err != nil {
+		log.Printf("Error finding products similar to %s: %v", productID, err)
+		http.Error(w, "Error finding similar products", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SimilarResponse{
+		ProductID: productID,
+		Cheaper:   opts.Cheaper,
+		Method:    method,
+		Hits:      similar,
+	})
+}
+
+func productOffersHandler(w http.ResponseWriter, productID string) {
 
 	// Check cache first
 	var offers []serpapi.Offer
@@ -121,6 +333,15 @@ func productOffersHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Cache miss for product %s", productID)
 
+	// Prefer merchant offers scraped by our parser, SerpApi is only a fallback
+	if ownOffers, err := storage.GetOffers(db, productID); err != nil {
+		log.Printf("Error getting stored offers for product %s: %v", productID, err)
+	} else if len(ownOffers) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ownOffers)
+		return
+	}
+
 	// Get product from Meilisearch
 	product, err := search.GetProduct(meiliClient, productID)
 	if err != nil {
@@ -147,6 +368,55 @@ func productOffersHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(offers)
 }
 
+// imageHandler serves images stored by the parser: /images/{hash}/{size}
+func imageHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/images/"), "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		http.Error(w, "Image hash is required", http.StatusBadRequest)
+		return
+	}
+
+	size := images.SizeMedium
+	if len(parts) > 1 {
+		size = parts[1]
+	}
+
+	webp := strings.Contains(r.Header.Get("Accept"), "image/webp")
+	switch r.URL.Query().Get("format") {
+	case "webp":
+		webp = true
+	case "jpg", "jpeg":
+		webp = false
+	}
+
+	key, err := images.ResolveKey(imageStore, parts[0], size, webp)
+	if os.IsNotExist(err) {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	content, modTime, err := imageStore.Open(key)
+	if os.IsNotExist(err) {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error opening image %s: %v", key, err)
+		http.Error(w, "Error reading image", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	// Содержимое по хешу никогда не меняется
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("Vary", "Accept")
+	http.ServeContent(w, r, path.Base(key), modTime, content)
+}
+
 func parserStartHandler(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -190,6 +460,144 @@ func parserStatusHandler(w http.ResponseWriter, r *http.Request) {
 	io.Copy(w, resp.Body)
 }
 
+func parserSelftestHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := http.Post("http://parser:8082/selftest", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		http.Error(w, "Error calling parser service", http.StatusInternalServerError)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// parserJobReportHandler proxies GET /api/admin/parser/jobs/{id}/report to the parser
+func parserJobReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/admin/parser/jobs/")
+	jobID, ok := strings.CutSuffix(path, "/report")
+	if !ok || jobID == "" || strings.Contains(jobID, "/") {
+		http.Error(w, "Expected /api/admin/parser/jobs/{id}/report", http.StatusNotFound)
+		return
+	}
+
+	resp, err := http.Get("http://parser:8082/jobs/" + url.PathEscape(jobID) + "/report")
+	if err != nil {
+		http.Error(w, "Error calling parser service", http.StatusInternalServerError)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// searchSettingsHandler manages relevance settings of the products index.
+// /api/admin/search/settings covers all settings, /api/admin/search/settings/{section} one of
+// synonyms, stop-words, typo-tolerance and ranking-rules. DELETE restores the defaults.
+// Changes are stored in Postgres and applied to Meilisearch right away.
+func searchSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	section := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/search/settings"), "/")
+
+	settings, err := storage.GetSearchSettings(db)
+	if err != nil {
+		log.Printf("Error getting search settings: %v", err)
+		http.Error(w, "Error getting search settings", http.StatusInternalServerError)
+		return
+	}
+	if settings == nil {
+		settings = &models.SearchSettings{}
+	}
+
+	target, ok := settingsSection(settings, section)
+	if !ok {
+		http.Error(w, "Unknown settings section: "+section, http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(target)
+		return
+	case http.MethodPut:
+		if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		resetSettingsSection(settings, section)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := search.ValidateSettings(*settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := storage.SaveSearchSettings(db, *settings); err != nil {
+		log.Printf("Error saving search settings: %v", err)
+		http.Error(w, "Error saving search settings", http.StatusInternalServerError)
+		return
+	}
+	if err := search.ApplySettings(meiliClient, *settings); err != nil {
+		log.Printf("Error applying search settings: %v", err)
+		http.Error(w, "Settings saved but not applied: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// settingsSection returns a pointer to a section of the settings, all settings for an empty section
+func settingsSection(settings *models.SearchSettings, section string) (interface{}, bool) {
+	switch section {
+	case "":
+		return settings, true
+	case "synonyms":
+		return &settings.Synonyms, true
+	case "stop-words":
+		return &settings.StopWords, true
+	case "typo-tolerance":
+		return &settings.TypoTolerance, true
+	case "ranking-rules":
+		return &settings.RankingRules, true
+	default:
+		return nil, false
+	}
+}
+
+// resetSettingsSection restores the Meilisearch defaults of a section, of all settings if it is empty
+func resetSettingsSection(settings *models.SearchSettings, section string) {
+	switch section {
+	case "":
+		*settings = models.SearchSettings{}
+	case "synonyms":
+		settings.Synonyms = nil
+	case "stop-words":
+		settings.StopWords = nil
+	case "typo-tolerance":
+		settings.TypoTolerance = nil
+	case "ranking-rules":
+		settings.RankingRules = nil
+	}
+}
+
 func apiKeysHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		geminiKeys := os.Getenv("GEMINI_API_KEYS")
@@ -268,6 +676,7 @@ func productsAdminHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
+		normalizer.ApplyFamily(&product)
 
 		if err := storage.AddProduct(db, product); err != nil {
 			log.Printf("Error adding product to database: %v", err)
@@ -288,6 +697,7 @@ func productsAdminHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
+		normalizer.ApplyFamily(&product)
 
 		if err := storage.UpdateProduct(db, product); err != nil {
 			log.Printf("Error updating product in database: %v", err)
@@ -358,9 +768,28 @@ func main() {
 	}
 	defer db.Close()
 
+	if err := storage.EnsureSchema(db); err != nil {
+		log.Fatalf("Failed to prepare database schema: %v", err)
+	}
+
+	// Initialize image store shared with the parser
+	imageStore, err = images.NewStore()
+	if err != nil {
+		log.Fatalf("Failed to create image store: %v", err)
+	}
+
 	// Initialize Meilisearch client (now includes configuration)
 	meiliClient = search.NewClient()
 
+	// Re-apply relevance settings managed through the admin API
+	if settings, err := storage.GetSearchSettings(db); err != nil {
+		log.Printf("Error loading search settings: %v", err)
+	} else if settings != nil {
+		if err := search.ApplySettings(meiliClient, *settings); err != nil {
+			log.Printf("Error applying search settings: %v", err)
+		}
+	}
+
 	// Index some sample products
 	task := search.IndexSampleProducts(meiliClient)
 	if task != nil {
@@ -373,21 +802,42 @@ func main() {
 		log.Println("Indexing task completed.")
 	}
 
+	// Hybrid search: vectors of the products index come from the selected embedder
+	embedder, err := embedding.NewFromEnv(strings.Split(geminiKeys, ","))
+	if err != nil {
+		log.Printf("Semantic search disabled: %v", err)
+	} else if embedder != nil {
+		if ratio, err := strconv.ParseFloat(os.Getenv("SEARCH_SEMANTIC_RATIO"), 64); err == nil {
+			search.DefaultSemanticRatio = ratio
+		}
+		if err := search.EnableHybrid(meiliClient, embedder); err != nil {
+			log.Printf("Semantic search disabled: %v", err)
+		} else {
+			search.StartEmbeddingSync(meiliClient, 10*time.Minute)
+		}
+	}
+
 	http.HandleFunc("/", withCORS(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "Hello from backend!")
 	}))
 
 	http.HandleFunc("/api/search", withCORS(searchHandler))
-	http.HandleFunc("/api/product/", withCORS(productOffersHandler))
+	http.HandleFunc("/api/suggest", withCORS(suggestHandler))
+	http.HandleFunc("/api/product/", withCORS(productHandler))
+	http.HandleFunc("/images/", withCORS(imageHandler))
 
 	// Admin endpoints
 	http.HandleFunc("/api/admin/parser/start", withCORS(parserStartHandler))
 	http.HandleFunc("/api/admin/parser/stop", withCORS(parserStopHandler))
 	http.HandleFunc("/api/admin/parser/status", withCORS(parserStatusHandler))
+	http.HandleFunc("/api/admin/parser/selftest", withCORS(parserSelftestHandler))
+	http.HandleFunc("/api/admin/parser/jobs/", withCORS(parserJobReportHandler))
 	http.HandleFunc("/api/admin/keys", withCORS(apiKeysHandler))
 	http.HandleFunc("/api/admin/logs/", withCORS(logsHandler))
 	http.HandleFunc("/api/admin/products/", withCORS(productsAdminHandler))
 	http.HandleFunc("/api/admin/statistics", withCORS(statisticsHandler))
+	http.HandleFunc("/api/admin/search/settings", withCORS(searchSettingsHandler))
+	http.HandleFunc("/api/admin/search/settings/", withCORS(searchSettingsHandler))
 
 	log.Println("Starting server on :8081")
 	if err := http.ListenAndServe(":8081", nil); err != nil {
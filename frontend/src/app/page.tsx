@@ -16,52 +16,126 @@ interface Product {
   features?: string[];
   google_product_id?: string;
   image_url?: string;
+  image_hash?: string;
+  price_eur?: number;
+  merchant_count?: number;
   price?: {
     price_gbp?: string;
     price_eur?: number;
     offer_count?: string;
   };
+  variants?: { id: string; color?: string; size?: string }[];
 }
 
+interface NormalizedQuery {
+  title: string;
+  category: string;
+  features: string[];
+}
+
+interface SearchResponse {
+  hits: Product[];
+  normalized: NormalizedQuery;
+  total_hits: number;
+  page: number;
+  page_size: number;
+  total_pages: number;
+  sort: string;
+  processing_time_ms: number;
+}
+
+const PAGE_SIZE = 12;
+
+const sortOptions = [
+  { value: 'relevance', label: 'Most relevant' },
+  { value: 'price_asc', label: 'Price: low to high' },
+  { value: 'price_desc', label: 'Price: high to low' },
+  { value: 'popularity', label: 'Most merchants' },
+  { value: 'newest', label: 'Newest' },
+];
+
 export default function Home() {
   const [results, setResults] = useState<Product[]>([]);
   const [loading, setLoading] = useState(false);
   const [hasSearched, setHasSearched] = useState(false);
+  const [query, setQuery] = useState('');
+  const [normalized, setNormalized] = useState<NormalizedQuery | null>(null);
+  const [sort, setSort] = useState('relevance');
+  const [page, setPage] = useState(1);
+  const [totalPages, setTotalPages] = useState(0);
+  const [totalHits, setTotalHits] = useState(0);
+  const [processingTime, setProcessingTime] = useState(0);
   const router = useRouter();
 
-  const handleSearch = async (query: string) => {
-    if (!query.trim()) return;
-    
+  const runSearch = async (
+    searchQuery: string,
+    options: { page: number; sort: string; normalized: NormalizedQuery | null },
+  ) => {
     setLoading(true);
     setHasSearched(true);
-    
+
     try {
       // Auto-detect language and region
-      const lang = detectLanguage(query);
+      const lang = detectLanguage(searchQuery);
       const region = detectRegion(lang);
-      
+
       const response = await fetch('http://localhost:8081/api/search', {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
         },
-        body: JSON.stringify({ query, lang, region }),
+        body: JSON.stringify({
+          query: searchQuery,
+          lang,
+          region,
+          // Paging and sorting reuse the normalized query, so the AI is not called again
+          normalized: options.normalized ?? undefined,
+          page: options.page,
+          page_size: PAGE_SIZE,
+          sort: options.sort,
+        }),
       });
-      
+
       if (!response.ok) {
         throw new Error('Search failed');
       }
-      
-      const data = await response.json();
-      setResults(data || []);
+
+      const data: SearchResponse = await response.json();
+      setResults(data.hits || []);
+      setNormalized(data.normalized);
+      setPage(data.page || 1);
+      setTotalPages(data.total_pages || 0);
+      setTotalHits(data.total_hits || 0);
+      setProcessingTime(data.processing_time_ms || 0);
     } catch (error) {
       console.error('Search error:', error);
       setResults([]);
+      setTotalPages(0);
+      setTotalHits(0);
     } finally {
       setLoading(false);
     }
   };
 
+  const handleSearch = async (searchQuery: string) => {
+    if (!searchQuery.trim()) return;
+
+    setQuery(searchQuery);
+    setNormalized(null);
+    await runSearch(searchQuery, { page: 1, sort, normalized: null });
+  };
+
+  const handleSortChange = (value: string) => {
+    setSort(value);
+    runSearch(query, { page: 1, sort: value, normalized });
+  };
+
+  const handlePageChange = (value: number) => {
+    if (value < 1 || value > totalPages) return;
+    runSearch(query, { page: value, sort, normalized });
+    window.scrollTo({ top: 0, behavior: 'smooth' });
+  };
+
   const handleProductClick = (product: Product) => {
     router.push(`/product/${product.id}`);
   };
@@ -251,13 +325,26 @@ export default function Home() {
                   />
                   
                   {!loading && results.length > 0 && (
-                    <motion.p
+                    <motion.div
                       initial={{ opacity: 0 }}
                       animate={{ opacity: 1 }}
-                      className="text-gray-600 dark:text-gray-400"
+                      className="flex flex-wrap items-center justify-between gap-4"
                     >
-                      Found {results.length} products
-                    </motion.p>
+                      <p className="text-gray-600 dark:text-gray-400">
+                        Found {totalHits} products in {processingTime} ms
+                      </p>
+                      <select
+                        value={sort}
+                        onChange={(e) => handleSortChange(e.target.value)}
+                        className="px-4 py-2 bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700 rounded-full text-gray-700 dark:text-gray-300"
+                      >
+                        {sortOptions.map((option) => (
+                          <option key={option.value} value={option.value}>
+                            {option.label}
+                          </option>
+                        ))}
+                      </select>
+                    </motion.div>
                   )}
                 </div>
 
@@ -282,6 +369,29 @@ export default function Home() {
                   </div>
                 )}
 
+                {/* Pagination */}
+                {!loading && totalPages > 1 && (
+                  <div className="flex items-center justify-center gap-4 mt-10">
+                    <button
+                      onClick={() => handlePageChange(page - 1)}
+                      disabled={page <= 1}
+                      className="px-4 py-2 bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700 rounded-full text-gray-700 dark:text-gray-300 disabled:opacity-50"
+                    >
+                      Previous
+                    </button>
+                    <span className="text-gray-600 dark:text-gray-400">
+                      Page {page} of {totalPages}
+                    </span>
+                    <button
+                      onClick={() => handlePageChange(page + 1)}
+                      disabled={page >= totalPages}
+                      className="px-4 py-2 bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700 rounded-full text-gray-700 dark:text-gray-300 disabled:opacity-50"
+                    >
+                      Next
+                    </button>
+                  </div>
+                )}
+
                 {/* No Results */}
                 {!loading && hasSearched && results.length === 0 && (
                   <motion.div
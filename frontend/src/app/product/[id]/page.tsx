@@ -21,18 +21,51 @@ import {
 import Image from 'next/image';
 import LoadingSpinner from '@/components/LoadingSpinner';
 
+interface ProductImage {
+  url: string;
+  hash?: string;
+}
+
+interface VariantOption {
+  id: string;
+  title: string;
+  color?: string;
+  size?: string;
+  price_eur?: number;
+}
+
 interface Product {
   id: string;
   title: string;
+  description?: string;
+  brand?: string;
   category?: string;
-  features?: string[];
+  category_path: string[];
+  features: string[];
+  specs: Record<string, string>;
+  images: ProductImage[];
+  price_eur?: number;
+  best_price?: {
+    price_eur: number;
+    merchant?: string;
+    link?: string;
+  };
+  offer_count: number;
+  url?: string;
   google_product_id?: string;
+  google_product_url?: string;
+  variant?: { color?: string; size?: string };
+  variants?: VariantOption[];
+}
+
+interface SimilarProduct {
+  id: string;
+  title: string;
+  category?: string;
   image_url?: string;
-  price?: {
-    price_gbp?: string;
-    price_eur?: number;
-    offer_count?: string;
-  };
+  image_hash?: string;
+  price_eur?: number;
+  shared_features?: string[];
 }
 
 interface Offer {
@@ -52,42 +85,53 @@ export default function ProductPage() {
   const [showOffers, setShowOffers] = useState(false);
   const [currentImageIndex, setCurrentImageIndex] = useState(0);
   const [isMinimized, setIsMinimized] = useState(false);
+  const [similar, setSimilar] = useState<SimilarProduct[]>([]);
+  const [cheaper, setCheaper] = useState<SimilarProduct[]>([]);
   
   const params = useParams();
   const router = useRouter();
   const { id } = params;
 
-  // Mock additional images for demo
-  const mockImages = [
-    product?.image_url || '/placeholder.jpg',
-    '/placeholder-2.jpg',
-    '/placeholder-3.jpg',
-    '/placeholder-4.jpg'
-  ].filter(Boolean);
+  // Stored images are served by the backend, others by their source
+  const images = (product?.images || [])
+    .map((image) => (image.hash ? `http://localhost:8081/images/${image.hash}/medium` : image.url))
+    .filter(Boolean);
+  if (images.length === 0) {
+    images.push('/placeholder.jpg');
+  }
 
   useEffect(() => {
     if (id) {
       fetchProduct();
+      fetchSimilar(false).then(setSimilar);
+      fetchSimilar(true).then(setCheaper);
     }
   }, [id]);
 
+  const fetchSimilar = async (cheaperOnly: boolean): Promise<SimilarProduct[]> => {
+    try {
+      const params = new URLSearchParams({ limit: '6', cheaper: String(cheaperOnly) });
+      const response = await fetch(
+        `http://localhost:8081/api/product/${encodeURIComponent(id as string)}/similar?${params}`,
+      );
+      if (!response.ok) return [];
+      const data = await response.json();
+      return data.hits || [];
+    } catch (error) {
+      console.error('Failed to fetch similar products:', error);
+      return [];
+    }
+  };
+
   const fetchProduct = async () => {
     try {
       setLoadingProduct(true);
-      // For demo purposes, we'll create a mock product since the backend endpoint might not exist
-      const mockProduct: Product = {
-        id: id as string,
-        title: "Apple iPhone 16 Pro Max, 256GB Desert Titanium",
-        category: "Smartphones",
-        features: ["256GB Storage", "Desert Titanium", "Pro Camera System", "A18 Pro Chip", "6.9-inch Display"],
-        image_url: "https://images.unsplash.com/photo-1592750475338-74b7b21085ab?w=500&h=500&fit=crop",
-        price: {
-          price_eur: 1299.99,
-          price_gbp: "£1,199.00",
-          offer_count: "15+ offers"
-        }
-      };
-      setProduct(mockProduct);
+      const response = await fetch(`http://localhost:8081/api/product/${encodeURIComponent(id as string)}`);
+      if (!response.ok) {
+        throw new Error(`Product request failed: ${response.status}`);
+      }
+      const data: Product = await response.json();
+      setProduct(data);
     } catch (error) {
       console.error('Failed to fetch product:', error);
     } finally {
@@ -102,34 +146,14 @@ export default function ProductPage() {
     setShowOffers(true);
     
     try {
-      // Mock offers for demo
-      const mockOffers: Offer[] = [
-        {
-          merchant: "Apple Store",
-          price: 1299.99,
-          link: "https://apple.com",
-          rating: 4.8,
-          shipping: "Free shipping",
-          availability: "In stock"
-        },
-        {
-          merchant: "Amazon",
-          price: 1279.99,
-          link: "https://amazon.com",
-          rating: 4.6,
-          shipping: "Free Prime shipping",
-          availability: "2-3 days"
-        },
-        {
-          merchant: "Best Buy",
-          price: 1299.99,
-          link: "https://bestbuy.com",
-          rating: 4.5,
-          shipping: "$9.99 shipping",
-          availability: "Pick up today"
-        }
-      ];
-      setOffers(mockOffers);
+      const response = await fetch(
+        `http://localhost:8081/api/product/${encodeURIComponent(product.id)}/offers`,
+      );
+      if (!response.ok) {
+        throw new Error(`Offers request failed: ${response.status}`);
+      }
+      const data: Offer[] = await response.json();
+      setOffers(data || []);
     } catch (error) {
       console.error('Failed to fetch offers:', error);
     } finally {
@@ -146,11 +170,11 @@ export default function ProductPage() {
   });
 
   const nextImage = () => {
-    setCurrentImageIndex((prev) => (prev + 1) % mockImages.length);
+    setCurrentImageIndex((prev) => (prev + 1) % images.length);
   };
 
   const prevImage = () => {
-    setCurrentImageIndex((prev) => (prev - 1 + mockImages.length) % mockImages.length);
+    setCurrentImageIndex((prev) => (prev - 1 + images.length) % images.length);
   };
 
   if (loadingProduct) {
@@ -240,7 +264,7 @@ export default function ProductPage() {
             {/* Main Image */}
             <div className="relative aspect-square bg-white dark:bg-gray-800 rounded-2xl overflow-hidden shadow-lg">
               <Image
-                src={mockImages[currentImageIndex]}
+                src={images[currentImageIndex]}
                 alt={product.title}
                 fill
                 className="object-cover"
@@ -248,7 +272,7 @@ export default function ProductPage() {
               />
               
               {/* Image Navigation */}
-              {mockImages.length > 1 && (
+              {images.length > 1 && (
                 <>
                   <button
                     onClick={prevImage}
@@ -267,9 +291,9 @@ export default function ProductPage() {
               )}
 
               {/* Image Indicators */}
-              {mockImages.length > 1 && (
+              {images.length > 1 && (
                 <div className="absolute bottom-4 left-1/2 -translate-x-1/2 flex space-x-2">
-                  {mockImages.map((_, index) => (
+                  {images.map((_, index) => (
                     <button
                       key={index}
                       onClick={() => setCurrentImageIndex(index)}
@@ -285,9 +309,9 @@ export default function ProductPage() {
             </div>
 
             {/* Thumbnail Images */}
-            {mockImages.length > 1 && (
+            {images.length > 1 && (
               <div className="grid grid-cols-4 gap-4">
-                {mockImages.map((image, index) => (
+                {images.map((image, index) => (
                   <motion.button
                     key={index}
                     onClick={() => setCurrentImageIndex(index)}
@@ -320,6 +344,11 @@ export default function ProductPage() {
           >
             {/* Product Info */}
             <div>
+              {product.category_path.length > 0 && (
+                <p className="text-sm text-gray-500 dark:text-gray-400 mb-2">
+                  {product.category_path.join(' › ')}
+                </p>
+              )}
               {product.category && (
                 <span className="inline-block px-3 py-1 bg-blue-100 dark:bg-blue-900 text-blue-800 dark:text-blue-200 text-sm font-medium rounded-full mb-4">
                   {product.category}
@@ -346,27 +375,63 @@ export default function ProductPage() {
               </div>
 
               {/* Price */}
-              {product.price && (
+              {product.best_price && (
                 <div className="mb-8">
                   <div className="flex items-baseline space-x-4">
                     <span className="text-4xl font-bold text-gray-900 dark:text-white">
-                      €{product.price.price_eur?.toFixed(2)}
+                      €{product.best_price.price_eur.toFixed(2)}
                     </span>
-                    {product.price.price_gbp && (
-                      <span className="text-xl text-gray-500 line-through">
-                        {product.price.price_gbp}
+                    {product.best_price.merchant && (
+                      <span className="text-lg text-gray-500">
+                        at {product.best_price.merchant}
                       </span>
                     )}
                   </div>
-                  {product.price.offer_count && (
+                  {product.offer_count > 0 && (
                     <p className="text-green-600 dark:text-green-400 font-medium mt-2">
-                      {product.price.offer_count} available
+                      {product.offer_count} offers available
                     </p>
                   )}
+                  {product.google_product_url && (
+                    <a
+                      href={product.google_product_url}
+                      target="_blank"
+                      rel="noopener noreferrer"
+                      className="inline-flex items-center text-sm text-blue-600 hover:underline mt-2"
+                    >
+                      View on Google Shopping
+                      <ExternalLink className="w-4 h-4 ml-1" />
+                    </a>
+                  )}
                 </div>
               )}
             </div>
 
+            {/* Variant Picker */}
+            {product.variants && product.variants.length > 1 && (
+              <div>
+                <h3 className="text-xl font-semibold text-gray-900 dark:text-white mb-4">
+                  Variants
+                </h3>
+                <div className="flex flex-wrap gap-2">
+                  {product.variants.map((variant) => (
+                    <button
+                      key={variant.id}
+                      onClick={() => variant.id !== product.id && router.push(`/product/${variant.id}`)}
+                      className={`px-4 py-2 rounded-lg border text-sm transition-colors ${
+                        variant.id === product.id
+                          ? 'border-blue-600 bg-blue-50 dark:bg-blue-900 text-blue-700 dark:text-blue-200'
+                          : 'border-gray-200 dark:border-gray-700 text-gray-700 dark:text-gray-300 hover:border-blue-400'
+                      }`}
+                    >
+                      {[variant.color, variant.size].filter(Boolean).join(' · ') || variant.title}
+                      {variant.price_eur ? ` – €${variant.price_eur.toFixed(2)}` : ''}
+                    </button>
+                  ))}
+                </div>
+              </div>
+            )}
+
             {/* Features */}
             {product.features && product.features.length > 0 && (
               <div>
@@ -514,6 +579,46 @@ export default function ProductPage() {
             </motion.div>
           )}
         </AnimatePresence>
+
+        {/* Related Products */}
+        {[
+          { title: 'Cheaper alternatives', items: cheaper },
+          { title: 'Similar products', items: similar },
+        ].map(
+          (section) =>
+            section.items.length > 0 && (
+              <section key={section.title} className="mt-12">
+                <h2 className="text-2xl font-bold text-gray-900 dark:text-white mb-6">
+                  {section.title}
+                </h2>
+                <div className="grid grid-cols-2 md:grid-cols-3 lg:grid-cols-6 gap-4">
+                  {section.items.map((item) => (
+                    <button
+                      key={item.id}
+                      onClick={() => router.push(`/product/${item.id}`)}
+                      className="text-left bg-white dark:bg-gray-800 rounded-xl p-3 shadow hover:shadow-lg transition-shadow"
+                    >
+                      <img
+                        src={
+                          (item.image_hash
+                            ? `http://localhost:8081/images/${item.image_hash}/thumb`
+                            : item.image_url) || '/placeholder.jpg'
+                        }
+                        alt={item.title}
+                        className="w-full aspect-square object-contain mb-2"
+                      />
+                      <p className="text-sm text-gray-900 dark:text-white line-clamp-2">{item.title}</p>
+                      {item.price_eur ? (
+                        <p className="text-sm font-semibold text-gray-900 dark:text-white mt-1">
+                          €{item.price_eur.toFixed(2)}
+                        </p>
+                      ) : null}
+                    </button>
+                  ))}
+                </div>
+              </section>
+            ),
+        )}
       </main>
 
       {/* Minimize Button - Fixed Position */}
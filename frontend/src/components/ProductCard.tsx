@@ -12,11 +12,13 @@ interface Product {
   features?: string[];
   google_product_id?: string;
   image_url?: string;
+  image_hash?: string;
   price?: {
     price_gbp?: string;
     price_eur?: number;
     offer_count?: string;
   };
+  variants?: { id: string; color?: string; size?: string }[];
 }
 
 interface ProductCardProps {
@@ -45,9 +47,9 @@ export default function ProductCard({ product, onClick, index = 0 }: ProductCard
       <div className="bg-white dark:bg-gray-800 rounded-2xl shadow-sm hover:shadow-xl transition-all duration-300 border border-gray-100 dark:border-gray-700 overflow-hidden">
         {/* Image Container */}
         <div className="relative aspect-square bg-gray-50 dark:bg-gray-900 overflow-hidden">
-          {product.image_url ? (
+          {product.image_hash || product.image_url ? (
             <Image
-              src={product.image_url}
+              src={product.image_hash ? `http://localhost:8081/images/${product.image_hash}/medium` : product.image_url!}
               alt={product.title}
               fill
               className="object-cover group-hover:scale-105 transition-transform duration-300"
@@ -86,6 +88,13 @@ export default function ProductCard({ product, onClick, index = 0 }: ProductCard
             {product.title}
           </h3>
 
+          {/* Variants of the product family collapsed into this hit */}
+          {product.variants && product.variants.length > 1 && (
+            <p className="text-sm text-gray-500 dark:text-gray-400 mb-2">
+              {product.variants.length} variants available
+            </p>
+          )}
+
           {/* Features */}
           {product.features && product.features.length > 0 && (
             <div className="flex flex-wrap gap-1 mb-3">
@@ -1,9 +1,16 @@
 'use client';
 
 import { useState, useRef, useEffect } from 'react';
-import { Search, Loader2, Mic, Camera } from 'lucide-react';
+import { Search, Loader2, Mic, Camera, TrendingUp } from 'lucide-react';
 import { motion, AnimatePresence } from 'framer-motion';
-import { cn } from '@/lib/utils';
+import { cn, detectLanguage, detectRegion } from '@/lib/utils';
+
+interface Suggestion {
+  text: string;
+  source: 'query' | 'product';
+  popularity: number;
+  product_id?: string;
+}
 
 interface SearchInputProps {
   onSearch: (query: string) => void;
@@ -20,6 +27,7 @@ export default function SearchInput({
 }: SearchInputProps) {
   const [query, setQuery] = useState('');
   const [isFocused, setIsFocused] = useState(false);
+  const [suggestions, setSuggestions] = useState<Suggestion[]>([]);
   const inputRef = useRef<HTMLInputElement>(null);
 
   useEffect(() => {
@@ -29,6 +37,38 @@ export default function SearchInput({
     }
   }, []);
 
+  useEffect(() => {
+    const prefix = query.trim();
+    if (!prefix) {
+      setSuggestions([]);
+      return;
+    }
+
+    // Debounce typing, suggestions come from the index and past queries without the AI
+    const controller = new AbortController();
+    const timer = setTimeout(async () => {
+      try {
+        const lang = detectLanguage(prefix);
+        const params = new URLSearchParams({ q: prefix, lang, region: detectRegion(lang) });
+        const response = await fetch(`http://localhost:8081/api/suggest?${params}`, {
+          signal: controller.signal,
+        });
+        if (!response.ok) return;
+        const data = await response.json();
+        setSuggestions(data.suggestions || []);
+      } catch (error) {
+        if ((error as Error).name !== 'AbortError') {
+          console.error('Suggest error:', error);
+        }
+      }
+    }, 150);
+
+    return () => {
+      clearTimeout(timer);
+      controller.abort();
+    };
+  }, [query]);
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     if (query.trim() && !loading) {
@@ -140,7 +180,7 @@ export default function SearchInput({
 
       {/* Search Suggestions */}
       <AnimatePresence>
-        {isFocused && query.length > 0 && (
+        {isFocused && query.length > 0 && suggestions.length > 0 && (
           <motion.div
             initial={{ opacity: 0, y: -10 }}
             animate={{ opacity: 1, y: 0 }}
@@ -148,22 +188,28 @@ export default function SearchInput({
             className="absolute top-full mt-2 w-full bg-white dark:bg-gray-800 rounded-2xl shadow-xl border border-gray-200 dark:border-gray-700 overflow-hidden z-50"
           >
             <div className="p-4">
-              <div className="text-sm text-gray-500 dark:text-gray-400 mb-2">Recent searches</div>
+              <div className="text-sm text-gray-500 dark:text-gray-400 mb-2">Suggestions</div>
               <div className="space-y-2">
-                {['iPhone 15 Pro', 'Samsung Galaxy S24', 'MacBook Air M3'].map((suggestion, index) => (
+                {suggestions.map((suggestion, index) => (
                   <motion.button
-                    key={suggestion}
+                    key={`${suggestion.source}-${suggestion.text}`}
                     initial={{ opacity: 0, x: -20 }}
                     animate={{ opacity: 1, x: 0 }}
                     transition={{ delay: index * 0.05 }}
                     className="flex items-center w-full p-2 text-left hover:bg-gray-50 dark:hover:bg-gray-700 rounded-lg transition-colors"
-                    onClick={() => {
-                      setQuery(suggestion);
-                      onSearch(suggestion);
+                    // onMouseDown fires before the input blur hides the list
+                    onMouseDown={(e) => {
+                      e.preventDefault();
+                      setQuery(suggestion.text);
+                      onSearch(suggestion.text);
                     }}
                   >
-                    <Search className="w-4 h-4 text-gray-400 mr-3" />
-                    <span className="text-gray-700 dark:text-gray-300">{suggestion}</span>
+                    {suggestion.source === 'query' ? (
+                      <Search className="w-4 h-4 text-gray-400 mr-3" />
+                    ) : (
+                      <TrendingUp className="w-4 h-4 text-gray-400 mr-3" />
+                    )}
+                    <span className="text-gray-700 dark:text-gray-300">{suggestion.text}</span>
                   </motion.button>
                 ))}
               </div>
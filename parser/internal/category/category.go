@@ -0,0 +1,114 @@
+package category
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// PathSeparator separates levels of a hierarchical category path
+const PathSeparator = " > "
+
+// ignoredCrumbs are breadcrumb entries that are not categories
+var ignoredCrumbs = map[string]bool{
+	"home":        true,
+	"start":       true,
+	"pricerunner": true,
+	"all":         true,
+	"categories":  true,
+}
+
+// urlCategoryPattern matches PriceRunner category list URLs like /cl/1/Mobile-Phones
+var urlCategoryPattern = regexp.MustCompile(`/cl/\d+/([^/?#]+)`)
+
+// Mapper translates source category names to the normalizer category vocabulary
+type Mapper struct {
+	mapping map[string]string
+}
+
+// NewMapper creates a mapper from a source name to vocabulary name table.
+// Keys are matched case-insensitively.
+func NewMapper(mapping map[string]string) *Mapper {
+	normalized := make(map[string]string, len(mapping))
+	for name, category := range mapping {
+		normalized[normalizeName(name)] = category
+	}
+	return &Mapper{mapping: normalized}
+}
+
+// Resolve returns the mapped category and the hierarchical path of a product.
+// The breadcrumb trail is preferred; the job category is used when the page has none.
+// The most specific level that has a mapping wins, otherwise the most specific level is used as is.
+func (m *Mapper) Resolve(jobCategory string, breadcrumbs []string, title string) (string, string) {
+	levels := cleanBreadcrumbs(breadcrumbs, title)
+	if len(levels) == 0 && jobCategory != "" {
+		levels = []string{jobCategory}
+	}
+	if len(levels) == 0 {
+		return "", ""
+	}
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		if mapped, ok := m.mapping[normalizeName(levels[i])]; ok {
+			return mapped, strings.Join(levels, PathSeparator)
+		}
+	}
+
+	// Категория задания точнее корневых уровней, если ничего не сопоставилось
+	if mapped, ok := m.mapping[normalizeName(jobCategory)]; ok {
+		return mapped, strings.Join(levels, PathSeparator)
+	}
+
+	return levels[len(levels)-1], strings.Join(levels, PathSeparator)
+}
+
+// FromURL derives a category name from the last path segment of a list URL
+func FromURL(listURL string) string {
+	parsed, err := url.Parse(listURL)
+	if err != nil {
+		return ""
+	}
+
+	segment := ""
+	if matches := urlCategoryPattern.FindStringSubmatch(parsed.Path); len(matches) == 2 {
+		segment = matches[1]
+	} else {
+		segment = path.Base(strings.TrimSuffix(parsed.Path, "/"))
+	}
+	if segment == "" || segment == "." || segment == "/" {
+		return ""
+	}
+
+	if unescaped, err := url.PathUnescape(segment); err == nil {
+		segment = unescaped
+	}
+	return strings.TrimSpace(strings.NewReplacer("-", " ", "_", " ").Replace(segment))
+}
+
+// cleanBreadcrumbs drops navigation entries, duplicates and the product itself from a trail
+func cleanBreadcrumbs(breadcrumbs []string, title string) []string {
+	var levels []string
+	seen := make(map[string]bool)
+
+	for _, crumb := range breadcrumbs {
+		crumb = strings.Join(strings.Fields(crumb), " ")
+		name := normalizeName(crumb)
+		if name == "" || ignoredCrumbs[name] || seen[name] {
+			continue
+		}
+		if title != "" && name == normalizeName(title) {
+			continue
+		}
+
+		seen[name] = true
+		levels = append(levels, crumb)
+	}
+
+	return levels
+}
+
+// normalizeName prepares a category name for case-insensitive lookup
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,205 @@
+package crawlpolicy
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"pricerunner-parser/internal/config"
+)
+
+// Cache lifetimes of fetched robots.txt files
+const (
+	defaultCacheTTL = 24 * time.Hour
+	errorCacheTTL   = 5 * time.Minute
+)
+
+// maxRobotsBytes limits the size of a robots.txt file
+const maxRobotsBytes = 512 << 10
+
+// Reasons of a blocked URL
+const (
+	ReasonDomain = "domain_not_allowed"
+	ReasonRobots = "robots_disallow"
+)
+
+// BlockedError is returned for a URL the crawl policy does not allow
+type BlockedError struct {
+	URL    string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("blocked by crawl policy (%s): %s", e.Reason, e.URL)
+}
+
+// Blocked describes a URL skipped by the crawl policy during a job
+type Blocked struct {
+	URL       string    `json:"url"`
+	Reason    string    `json:"reason"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// cachedRobots is a robots.txt of a host with its expiry time
+type cachedRobots struct {
+	robots    *Robots
+	expiresAt time.Time
+}
+
+// Policy decides which URLs may be crawled and how often a host may be requested.
+// It honors robots.txt Disallow and Crawl-delay for the configured user agent
+// and an optional allow-list of domains.
+type Policy struct {
+	userAgent      string
+	respectRobots  bool
+	allowedDomains []string
+	cacheTTL       time.Duration
+	client         *http.Client
+
+	mu          sync.Mutex
+	robots      map[string]cachedRobots
+	lastRequest map[string]time.Time
+}
+
+// New creates a crawl policy from the configuration
+func New(cfg config.CrawlPolicyConfig) *Policy {
+	policy := &Policy{
+		userAgent:     cfg.UserAgent,
+		respectRobots: cfg.RespectRobots,
+		cacheTTL:      time.Duration(cfg.CacheTTLMinutes) * time.Minute,
+		client:        &http.Client{Timeout: 15 * time.Second},
+		robots:        make(map[string]cachedRobots),
+		lastRequest:   make(map[string]time.Time),
+	}
+	if policy.cacheTTL <= 0 {
+		policy.cacheTTL = defaultCacheTTL
+	}
+	for _, domain := range cfg.AllowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "."))
+		if domain != "" {
+			policy.allowedDomains = append(policy.allowedDomains, domain)
+		}
+	}
+	return policy
+}
+
+// UserAgent returns the user agent requests should be sent with
+func (p *Policy) UserAgent() string {
+	return p.userAgent
+}
+
+// Check returns a *BlockedError if the URL is outside the allowed domains or disallowed by robots.txt
+func (p *Policy) Check(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid URL: %s", rawURL)
+	}
+
+	if !p.domainAllowed(parsed.Hostname()) {
+		return &BlockedError{URL: rawURL, Reason: ReasonDomain}
+	}
+
+	if p.respectRobots && !p.robotsFor(parsed).Allowed(parsed.RequestURI()) {
+		return &BlockedError{URL: rawURL, Reason: ReasonRobots}
+	}
+
+	return nil
+}
+
+// Wait sleeps until the Crawl-delay of the URL host has passed since the previous request
+func (p *Policy) Wait(rawURL string) {
+	if !p.respectRobots {
+		return
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return
+	}
+
+	delay := p.robotsFor(parsed).CrawlDelay()
+	host := strings.ToLower(parsed.Host)
+
+	p.mu.Lock()
+	next := p.lastRequest[host].Add(delay)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	// Следующий запрос к хосту планируется от зарезервированного времени
+	p.lastRequest[host] = next
+	p.mu.Unlock()
+
+	if wait := time.Until(next); wait > 0 {
+		log.Printf("    ⏱ Crawl-delay for %s: waiting %v", host, wait.Round(time.Millisecond))
+		time.Sleep(wait)
+	}
+}
+
+// domainAllowed reports whether a host is one of the allowed domains or their subdomain
+func (p *Policy) domainAllowed(host string) bool {
+	if len(p.allowedDomains) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(host)
+	for _, domain := range p.allowedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// robotsFor returns the cached robots.txt rules of the URL host, fetching them when expired
+func (p *Policy) robotsFor(parsed *url.URL) *Robots {
+	key := parsed.Scheme + "://" + strings.ToLower(parsed.Host)
+
+	p.mu.Lock()
+	cached, ok := p.robots[key]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.robots
+	}
+
+	robots, ttl := p.fetchRobots(key)
+
+	p.mu.Lock()
+	p.robots[key] = cachedRobots{robots: robots, expiresAt: time.Now().Add(ttl)}
+	p.mu.Unlock()
+
+	return robots
+}
+
+// fetchRobots downloads and parses robots.txt of a site.
+// A missing file allows everything, an unreachable one disallows everything for a short time.
+func (p *Policy) fetchRobots(site string) (*Robots, time.Duration) {
+	req, err := http.NewRequest("GET", site+"/robots.txt", nil)
+	if err != nil {
+		return allowAll, p.cacheTTL
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Printf("Warning: failed to fetch %s/robots.txt: %v", site, err)
+		return &Robots{disallowAll: true}, errorCacheTTL
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		log.Printf("Warning: %s/robots.txt returned %d, treating the site as disallowed", site, resp.StatusCode)
+		return &Robots{disallowAll: true}, errorCacheTTL
+	case resp.StatusCode >= 400:
+		return allowAll, p.cacheTTL
+	}
+
+	robots := parseRobots(http.MaxBytesReader(nil, resp.Body, maxRobotsBytes), p.userAgent)
+	log.Printf("Loaded %s/robots.txt (%d rules, crawl-delay %v)", site, len(robots.rules), robots.crawlDelay)
+	return robots, p.cacheTTL
+}
@@ -0,0 +1,176 @@
+package crawlpolicy
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rule is one Allow or Disallow line of a robots.txt group
+type rule struct {
+	pattern string
+	allow   bool
+}
+
+// group contains the rules that apply to one or more user agents
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+// Robots contains the parsed robots.txt rules for the configured user agent
+type Robots struct {
+	rules      []rule
+	crawlDelay time.Duration
+	// disallowAll is set when robots.txt could not be fetched because of a server error
+	disallowAll bool
+}
+
+// allowAll is used when a host has no robots.txt
+var allowAll = &Robots{}
+
+// parseRobots parses robots.txt and keeps the group of userAgent, or the "*" group if there is none.
+// Groups are matched by the product token of userAgent, exactly and ignoring case.
+func parseRobots(body io.Reader, userAgent string) *Robots {
+	var groups []*group
+	var current *group
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Несколько строк User-agent подряд относятся к одной группе
+			if current == nil || !lastWasAgent {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			// Сравнивается только имя продукта: "MyBot/2.0" относится к MyBot
+			if agent := strings.ToLower(productToken(value)); agent != "" {
+				current.agents = append(current.agents, agent)
+			}
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			// Пустой Disallow разрешает все
+			if current != nil && value != "" {
+				current.rules = append(current.rules, rule{pattern: value, allow: key == "allow"})
+			}
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					current.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+		lastWasAgent = false
+	}
+
+	token := strings.ToLower(productToken(userAgent))
+	var wildcard, matched *group
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = g
+				}
+			case token != "" && agent == token:
+				if matched == nil {
+					matched = g
+				}
+			}
+		}
+	}
+	if matched == nil {
+		matched = wildcard
+	}
+	if matched == nil {
+		return allowAll
+	}
+
+	return &Robots{rules: matched.rules, crawlDelay: matched.crawlDelay}
+}
+
+// Allowed reports whether a path (with query) may be crawled.
+// The longest matching rule wins, Allow wins a tie.
+func (r *Robots) Allowed(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allowed := true
+	longest := -1
+	for _, rule := range r.rules {
+		if !matchPattern(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			longest = len(rule.pattern)
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns the requested delay between requests to the host
+func (r *Robots) CrawlDelay() time.Duration {
+	return r.crawlDelay
+}
+
+// matchPattern matches a robots.txt path pattern with * wildcards and a $ end anchor
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for _, part := range parts[1:] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+
+	if anchored {
+		// Последний фрагмент после * должен совпасть с концом пути
+		last := parts[len(parts)-1]
+		if len(parts) == 1 {
+			return rest == ""
+		}
+		return strings.HasSuffix(path, last)
+	}
+	return true
+}
+
+// productToken returns the name part of a user agent, "MyBot/1.0 (+url)" -> "MyBot"
+func productToken(userAgent string) string {
+	fields := strings.Fields(userAgent)
+	if len(fields) == 0 {
+		return ""
+	}
+	name, _, _ := strings.Cut(fields[0], "/")
+	return name
+}
@@ -0,0 +1,41 @@
+package crawlpolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobotsAgentMatch(t *testing.T) {
+	const body = `User-agent: Bot
+Disallow: /bot
+
+User-agent: priceparserbot/2.0
+Disallow: /parser
+
+User-agent:
+Disallow: /empty
+
+User-agent: *
+Disallow: /all
+`
+
+	tests := []struct {
+		userAgent string
+		blocked   string
+	}{
+		{"PriceParserBot/1.0 (+https://github.com/GoladorYeah/gemini)", "/parser"},
+		{"Bot/1.0", "/bot"},
+		{"OtherBot/1.0", "/all"},
+		{"", "/all"},
+	}
+
+	paths := []string{"/bot", "/parser", "/empty", "/all"}
+	for _, test := range tests {
+		robots := parseRobots(strings.NewReader(body), test.userAgent)
+		for _, path := range paths {
+			if allowed := robots.Allowed(path); allowed == (path == test.blocked) {
+				t.Errorf("%q: Allowed(%s) = %v", test.userAgent, path, allowed)
+			}
+		}
+	}
+}
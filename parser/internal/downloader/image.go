@@ -1,91 +1,228 @@
 package downloader
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
+
+	_ "image/gif" // GIF decoder
+	_ "image/png" // PNG decoder
+
+	"pricerunner-parser/internal/crawlpolicy"
+	"pricerunner-parser/internal/models"
+	"pricerunner-parser/internal/proxy"
+	"pricerunner-parser/internal/retry"
+
+	"github.com/HugoSmits86/nativewebp"
+	_ "golang.org/x/image/bmp" // BMP decoder
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebP decoder
 )
 
-// ImageDownloader handles image downloading
+// Image sizes stored next to the original
+const (
+	SizeOriginal = "original"
+	SizeMedium   = "medium"
+	SizeThumb    = "thumb"
+)
+
+// sizeLimits contains the maximum width and height of each generated size
+var sizeLimits = map[string]int{
+	SizeMedium: 600,
+	SizeThumb:  200,
+}
+
+// maxImageBytes limits the size of a downloaded image
+const maxImageBytes = 20 << 20
+
+// mimeExtensions maps sniffed MIME types to file extensions of the original
+var mimeExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+	"image/bmp":  "bmp",
+	"image/avif": "avif",
+}
+
+// ImageDownloader handles image downloading into a content-addressed object store.
+// Every image is stored once under its SHA-256 hash:
+// {hash[:2]}/{hash}/original.{ext}, medium.jpg, medium.webp, thumb.jpg, thumb.webp
 type ImageDownloader struct {
-	imagesDir string
-	client    *http.Client
+	store   ObjectStore
+	client  *http.Client
+	retry   retry.Policy
+	proxies *proxy.Pool
+	crawl   *crawlpolicy.Policy
 }
 
-// NewImageDownloader creates a new image downloader
-func NewImageDownloader(imagesDir string) *ImageDownloader {
+// NewImageDownloader creates a new image downloader writing into store.
+// Downloads are retried by policy, rotated over the proxy pool and checked against the crawl policy.
+func NewImageDownloader(store ObjectStore, policy retry.Policy, proxies *proxy.Pool, crawl *crawlpolicy.Policy) *ImageDownloader {
 	return &ImageDownloader{
-		imagesDir: imagesDir,
+		store: store,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: proxy.Transport(),
 		},
+		retry:   policy,
+		proxies: proxies,
+		crawl:   crawl,
 	}
 }
 
-// DownloadImage downloads an image and returns the local path
-func (d *ImageDownloader) DownloadImage(url, productID string) (string, error) {
+// DownloadImage downloads an image, stores it by content hash with resized variants
+// and returns its metadata. Local of the result is the object key of the original.
+// Images already in the store are not written again.
+func (d *ImageDownloader) DownloadImage(url string) (*models.ImageInfo, error) {
 	if url == "" || strings.HasPrefix(url, "data:") {
-		return "", fmt.Errorf("invalid URL: %s", url)
+		return nil, fmt.Errorf("invalid URL: %s", url)
 	}
 
 	// Нормализуем URL
 	normalizedURL := d.normalizeURL(url)
 
-	// Определяем расширение файла
-	ext := d.extractExtension(normalizedURL)
+	data, err := d.fetch(normalizedURL)
+	if err != nil {
+		return nil, err
+	}
 
-	// Создаем безопасное имя файла
-	safeProductID := d.sanitizeFilename(productID)
-	filename := fmt.Sprintf("%s.%s", safeProductID, ext)
-	localPath := filepath.Join(d.imagesDir, filename)
+	// Определяем тип по содержимому, а не по расширению в URL
+	mimeType := sniffMIME(data)
+	ext, ok := mimeExtensions[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported content type %s", mimeType)
+	}
 
-	// Проверяем, существует ли файл
-	if _, err := os.Stat(localPath); err == nil {
-		return localPath, nil // Файл уже существует
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	info := &models.ImageInfo{
+		URL:   url,
+		Hash:  hash,
+		MIME:  mimeType,
+		Bytes: int64(len(data)),
+	}
+
+	originalKey := ObjectKey(hash, fmt.Sprintf("%s.%s", SizeOriginal, ext))
+	info.Local = originalKey
+
+	if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		info.Width = config.Width
+		info.Height = config.Height
+	}
+
+	// Такое изображение уже есть в хранилище
+	exists, err := d.store.Exists(originalKey)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return info, nil
+	}
+
+	// Сначала варианты, затем оригинал: наличие оригинала означает, что запись завершена
+	if err := d.writeVariants(hash, data); err != nil {
+		return nil, err
+	}
+	if err := d.store.Put(originalKey, data, mimeType); err != nil {
+		return nil, err
 	}
 
+	return info, nil
+}
+
+// fetch downloads the image body, retrying temporary failures through the next proxy
+func (d *ImageDownloader) fetch(url string) ([]byte, error) {
+	if err := d.crawl.Check(url); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err := d.retry.Do("image download", func(attempt int) error {
+		d.crawl.Wait(url)
+		selected := d.proxies.Next()
+		var err error
+		data, err = d.fetchOnce(url, selected)
+		d.proxies.Report(selected, err)
+		return err
+	})
+	return data, err
+}
+
+// fetchOnce makes one download request through the given proxy, nil for a direct connection
+func (d *ImageDownloader) fetchOnce(url string, selected *proxy.Proxy) ([]byte, error) {
 	// Создаем HTTP запрос
-	req, err := http.NewRequest("GET", normalizedURL, nil)
+	req, err := http.NewRequestWithContext(proxy.WithProxy(context.Background(), selected), "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Устанавливаем заголовки
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8")
+	req.Header.Set("User-Agent", d.crawl.UserAgent())
+	req.Header.Set("Accept", "image/webp,image/apng,image/*,*/*;q=0.8")
 	req.Header.Set("Referer", "https://www.pricerunner.com/")
 
 	// Выполняем запрос
 	resp, err := d.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
+		return nil, fmt.Errorf("failed to download image: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("bad status: %d", resp.StatusCode)
+		return nil, &retry.StatusError{Code: resp.StatusCode}
 	}
 
-	// Создаем файл
-	file, err := os.Create(localPath)
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxImageBytes)
 	}
-	defer file.Close()
 
-	// Копируем содержимое
-	_, err = io.Copy(file, resp.Body)
+	return data, nil
+}
+
+// writeVariants generates resized JPEG and WebP versions of the image.
+// Formats without a Go decoder (AVIF) are stored as original only.
+func (d *ImageDownloader) writeVariants(hash string, data []byte) error {
+	src, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
-		os.Remove(localPath) // Удаляем частично загруженный файл
-		return "", fmt.Errorf("failed to save image: %w", err)
+		return nil
+	}
+
+	for size, limit := range sizeLimits {
+		resized := resize(src, limit)
+
+		var jpegBuf bytes.Buffer
+		if err := jpeg.Encode(&jpegBuf, flatten(resized), &jpeg.Options{Quality: 85}); err != nil {
+			return fmt.Errorf("failed to encode %s jpeg: %w", size, err)
+		}
+		if err := d.store.Put(ObjectKey(hash, size+".jpg"), jpegBuf.Bytes(), "image/jpeg"); err != nil {
+			return err
+		}
+
+		var webpBuf bytes.Buffer
+		if err := nativewebp.Encode(&webpBuf, resized, nil); err != nil {
+			return fmt.Errorf("failed to encode %s webp: %w", size, err)
+		}
+		if err := d.store.Put(ObjectKey(hash, size+".webp"), webpBuf.Bytes(), "image/webp"); err != nil {
+			return err
+		}
 	}
 
-	return localPath, nil
+	return nil
 }
 
 // normalizeURL нормализует URL изображения
@@ -99,27 +236,41 @@ func (d *ImageDownloader) normalizeURL(url string) string {
 	return url
 }
 
-// extractExtension извлекает расширение файла из URL
-func (d *ImageDownloader) extractExtension(url string) string {
-	re := regexp.MustCompile(`\.([a-zA-Z0-9]+)(?:\?|$)`)
-	matches := re.FindStringSubmatch(url)
-	if len(matches) > 1 {
-		ext := strings.ToLower(matches[1])
-		// Проверяем, что это действительно расширение изображения
-		validExts := map[string]bool{
-			"jpg": true, "jpeg": true, "png": true, "gif": true,
-			"webp": true, "avif": true, "svg": true, "bmp": true,
-		}
-		if validExts[ext] {
-			return ext
-		}
+// sniffMIME detects the image type from its first bytes
+func sniffMIME(data []byte) string {
+	// AVIF не распознается http.DetectContentType
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" && (string(data[8:12]) == "avif" || string(data[8:12]) == "avis") {
+		return "image/avif"
 	}
-	return "jpg" // По умолчанию
+	return http.DetectContentType(data)
+}
+
+// resize scales an image down to fit into a limit x limit box, smaller images are kept as is
+func resize(src image.Image, limit int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= limit && height <= limit {
+		return src
+	}
+
+	if width >= height {
+		height = max(1, height*limit/width)
+		width = limit
+	} else {
+		width = max(1, width*limit/height)
+		height = limit
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
 }
 
-// sanitizeFilename создает безопасное имя файла
-func (d *ImageDownloader) sanitizeFilename(filename string) string {
-	// Заменяем недопустимые символы на подчеркивания
-	re := regexp.MustCompile(`[^\w\-]`)
-	return re.ReplaceAllString(filename, "_")
+// flatten draws an image on a white background, JPEG has no transparency
+func flatten(src image.Image) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
 }
@@ -0,0 +1,145 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"pricerunner-parser/internal/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// ObjectStore stores image files under stable object keys like "ab/abcd…/original.jpg".
+// The backend resolves the same keys against the same store.
+type ObjectStore interface {
+	// Put writes an object, replacing an existing one
+	Put(key string, data []byte, contentType string) error
+	// Exists reports whether an object is present
+	Exists(key string) (bool, error)
+}
+
+// storeTimeout limits a single object store request
+const storeTimeout = 30 * time.Second
+
+// ObjectKey returns the key of a file of an image hash
+func ObjectKey(hash, name string) string {
+	return path.Join(hash[:2], hash, name)
+}
+
+// NewObjectStore creates the image store selected in the storage config
+func NewObjectStore(cfg config.StorageConfig) (ObjectStore, error) {
+	switch cfg.Images.Type {
+	case "", "filesystem":
+		return NewFileStore(cfg.ImagesDir), nil
+	case "s3":
+		return NewS3Store(cfg.Images.S3)
+	default:
+		return nil, fmt.Errorf("unknown image store type: %s", cfg.Images.Type)
+	}
+}
+
+// FileStore keeps objects as files below a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a filesystem object store
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Put writes an object through a temporary file so readers never see a partial image
+func (s *FileStore) Put(key string, data []byte, contentType string) error {
+	filePath := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create image directory: %w", err)
+	}
+
+	tmp := filePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	if err := os.Rename(tmp, filePath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether the object file is present
+func (s *FileStore) Exists(key string) (bool, error) {
+	_, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// S3Store keeps objects in a bucket of an S3-compatible storage such as MinIO
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store connects to an S3-compatible storage and creates the bucket if it is missing
+func NewS3Store(cfg config.S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", cfg.Bucket, err)
+		}
+	}
+
+	return &S3Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+// Put uploads an object. Content by hash never changes, so it is marked immutable.
+func (s *S3Store) Put(key string, data []byte, contentType string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload image %s: %w", key, err)
+	}
+	return nil
+}
+
+// Exists reports whether the object is present in the bucket
+func (s *S3Store) Exists(key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	_, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check image %s: %w", key, err)
+}
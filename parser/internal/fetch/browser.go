@@ -0,0 +1,163 @@
+package fetch
+
+import (
+	"pricerunner-parser/internal/proxy"
+	"pricerunner-parser/internal/retry"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// BrowserFetcher renders pages in a Playwright browser context
+type BrowserFetcher struct {
+	newContext func(selected *proxy.Proxy) (playwright.BrowserContext, error)
+	timeout    float64
+}
+
+// NewBrowserFetcher creates a fetcher opening every page in a new context created by newContext.
+// timeout is the navigation timeout in milliseconds.
+func NewBrowserFetcher(newContext func(selected *proxy.Proxy) (playwright.BrowserContext, error), timeout int) *BrowserFetcher {
+	return &BrowserFetcher{newContext: newContext, timeout: float64(timeout)}
+}
+
+// Fetch creates a browser context and opens the URL in a new page
+func (f *BrowserFetcher) Fetch(url string, selected *proxy.Proxy) (Document, error) {
+	context, err := f.newContext(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := context.NewPage()
+	if err != nil {
+		context.Close()
+		return nil, err
+	}
+
+	response, err := page.Goto(url, playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+		Timeout:   playwright.Float(f.timeout),
+	})
+	if err != nil {
+		context.Close()
+		return nil, err
+	}
+
+	status := 0
+	var size int64
+	if response != nil {
+		status = response.Status()
+		if body, err := response.Body(); err == nil {
+			size = int64(len(body))
+		}
+	}
+
+	// Временные ответы сервера повторяем, 404 и остальные обрабатывает вызывающий код
+	if retry.RetryableStatus(status) {
+		context.Close()
+		return nil, &retry.StatusError{Code: status}
+	}
+
+	return &browserDocument{page: page, context: context, status: status, size: size}, nil
+}
+
+// browserDocument is a page rendered by Playwright
+type browserDocument struct {
+	page    playwright.Page
+	context playwright.BrowserContext
+	status  int
+	size    int64
+}
+
+// NewBrowserDocument wraps a Playwright page. Close closes context, which may be nil.
+func NewBrowserDocument(page playwright.Page, context playwright.BrowserContext, status int) Document {
+	return &browserDocument{page: page, context: context, status: status}
+}
+
+func (d *browserDocument) QuerySelector(selector string) (Element, error) {
+	return wrapHandle(d.page.QuerySelector(selector))
+}
+
+func (d *browserDocument) QuerySelectorAll(selector string) ([]Element, error) {
+	return wrapHandles(d.page.QuerySelectorAll(selector))
+}
+
+func (d *browserDocument) URL() string {
+	return d.page.URL()
+}
+
+func (d *browserDocument) Status() int {
+	return d.status
+}
+
+func (d *browserDocument) Size() int64 {
+	return d.size
+}
+
+func (d *browserDocument) InnerText(selector string) (string, error) {
+	return d.page.InnerText(selector)
+}
+
+func (d *browserDocument) HTML() (string, error) {
+	return d.page.Content()
+}
+
+func (d *browserDocument) Browser() playwright.Page {
+	return d.page
+}
+
+func (d *browserDocument) Close() {
+	if d.context != nil {
+		d.context.Close()
+	}
+}
+
+// browserElement is an element handle of a rendered page
+type browserElement struct {
+	handle playwright.ElementHandle
+}
+
+// Handle returns the Playwright handle of an element of a rendered page, nil for static HTML
+func Handle(element Element) playwright.ElementHandle {
+	if browser, ok := element.(*browserElement); ok {
+		return browser.handle
+	}
+	return nil
+}
+
+func (e *browserElement) QuerySelector(selector string) (Element, error) {
+	return wrapHandle(e.handle.QuerySelector(selector))
+}
+
+func (e *browserElement) QuerySelectorAll(selector string) ([]Element, error) {
+	return wrapHandles(e.handle.QuerySelectorAll(selector))
+}
+
+func (e *browserElement) InnerText() (string, error) {
+	return e.handle.InnerText()
+}
+
+func (e *browserElement) TextContent() (string, error) {
+	return e.handle.TextContent()
+}
+
+func (e *browserElement) GetAttribute(name string) (string, error) {
+	return e.handle.GetAttribute(name)
+}
+
+// wrapHandle converts a query result, keeping a missing element nil
+func wrapHandle(handle playwright.ElementHandle, err error) (Element, error) {
+	if err != nil || handle == nil {
+		return nil, err
+	}
+	return &browserElement{handle: handle}, nil
+}
+
+func wrapHandles(handles []playwright.ElementHandle, err error) ([]Element, error) {
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]Element, len(handles))
+	for i, handle := range handles {
+		elements[i] = &browserElement{handle: handle}
+	}
+	return elements, nil
+}
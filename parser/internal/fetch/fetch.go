@@ -0,0 +1,57 @@
+package fetch
+
+import (
+	"github.com/playwright-community/playwright-go"
+
+	"pricerunner-parser/internal/proxy"
+)
+
+// Fetch modes of a source
+const (
+	// ModeBrowser renders every page in Chromium
+	ModeBrowser = "browser"
+	// ModeStatic loads pages with net/http and parses the HTML, the browser is used only as a fallback
+	ModeStatic = "static"
+)
+
+// Node is a page or an element that can be queried with CSS selectors
+type Node interface {
+	// QuerySelector returns the first matching element, nil if there is none
+	QuerySelector(selector string) (Element, error)
+	QuerySelectorAll(selector string) ([]Element, error)
+}
+
+// Element is one element of a loaded page
+type Element interface {
+	Node
+	InnerText() (string, error)
+	TextContent() (string, error)
+	// GetAttribute returns an empty string if the attribute is missing
+	GetAttribute(name string) (string, error)
+}
+
+// Document is a loaded page, rendered by the browser or parsed from static HTML
+type Document interface {
+	Node
+	URL() string
+	// Status returns the HTTP status of the main response
+	Status() int
+	// Size returns the body size of the main response in bytes
+	Size() int64
+	// InnerText returns the text of the first element matching selector
+	InnerText(selector string) (string, error)
+	// HTML returns the current markup of the page
+	HTML() (string, error)
+	// Browser returns the Playwright page of a rendered document, nil for static HTML.
+	// Scrolling and waiting for lazy content are only possible in the browser.
+	Browser() playwright.Page
+	// Close releases the browser context of the page
+	Close()
+}
+
+// Fetcher loads pages of a source
+type Fetcher interface {
+	// Fetch loads a URL through the given proxy, nil for a direct connection.
+	// Responses with a retryable status are returned as *retry.StatusError.
+	Fetch(url string, selected *proxy.Proxy) (Document, error)
+}
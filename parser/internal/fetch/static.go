@@ -0,0 +1,244 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"pricerunner-parser/internal/proxy"
+	"pricerunner-parser/internal/retry"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/playwright-community/playwright-go"
+	"golang.org/x/net/html"
+)
+
+// maxPageBytes limits the size of a downloaded HTML page
+const maxPageBytes = 10 << 20
+
+// blockElements start a new line in the text of an element, like innerText in the browser
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+// sourceSpaces matches whitespace of the HTML source, rendered as a single space
+var sourceSpaces = regexp.MustCompile(`\s+`)
+
+// hiddenElements have no rendered text
+var hiddenElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "head": true,
+}
+
+// StaticFetcher loads pages with net/http without running their scripts
+type StaticFetcher struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewStaticFetcher creates a fetcher sending requests with the given user agent.
+// timeout is the request timeout in milliseconds.
+func NewStaticFetcher(userAgent string, timeout int) *StaticFetcher {
+	return &StaticFetcher{
+		client: &http.Client{
+			Timeout:   time.Duration(timeout) * time.Millisecond,
+			Transport: proxy.Transport(),
+		},
+		userAgent: userAgent,
+	}
+}
+
+// Fetch downloads a page and parses its HTML
+func (f *StaticFetcher) Fetch(url string, selected *proxy.Proxy) (Document, error) {
+	req, err := http.NewRequestWithContext(proxy.WithProxy(context.Background(), selected), "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if retry.RetryableStatus(resp.StatusCode) {
+		return nil, &retry.StatusError{Code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %w", err)
+	}
+
+	return ParseHTML(resp.Request.URL.String(), resp.StatusCode, body)
+}
+
+// staticDocument is a page parsed from HTML without a browser
+type staticDocument struct {
+	url    string
+	status int
+	body   []byte
+	doc    *goquery.Document
+}
+
+// ParseHTML creates a document from page markup
+func ParseHTML(url string, status int, body []byte) (Document, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	return &staticDocument{url: url, status: status, body: body, doc: doc}, nil
+}
+
+func (d *staticDocument) QuerySelector(selector string) (Element, error) {
+	return querySelector(d.doc.Selection, selector)
+}
+
+func (d *staticDocument) QuerySelectorAll(selector string) ([]Element, error) {
+	return querySelectorAll(d.doc.Selection, selector)
+}
+
+func (d *staticDocument) URL() string {
+	return d.url
+}
+
+func (d *staticDocument) Status() int {
+	return d.status
+}
+
+func (d *staticDocument) Size() int64 {
+	return int64(len(d.body))
+}
+
+func (d *staticDocument) InnerText(selector string) (string, error) {
+	element, err := d.QuerySelector(selector)
+	if err != nil {
+		return "", err
+	}
+	if element == nil {
+		return "", fmt.Errorf("no element matches %s", selector)
+	}
+	return element.InnerText()
+}
+
+func (d *staticDocument) HTML() (string, error) {
+	return string(d.body), nil
+}
+
+func (d *staticDocument) Browser() playwright.Page {
+	return nil
+}
+
+func (d *staticDocument) Close() {}
+
+// staticElement is one node of a parsed page
+type staticElement struct {
+	selection *goquery.Selection
+}
+
+func (e *staticElement) QuerySelector(selector string) (Element, error) {
+	return querySelector(e.selection, selector)
+}
+
+func (e *staticElement) QuerySelectorAll(selector string) ([]Element, error) {
+	return querySelectorAll(e.selection, selector)
+}
+
+func (e *staticElement) InnerText() (string, error) {
+	var text strings.Builder
+	for _, node := range e.selection.Nodes {
+		writeInnerText(&text, node)
+	}
+	return normalizeText(text.String()), nil
+}
+
+func (e *staticElement) TextContent() (string, error) {
+	return e.selection.Text(), nil
+}
+
+func (e *staticElement) GetAttribute(name string) (string, error) {
+	value, _ := e.selection.Attr(name)
+	return value, nil
+}
+
+// querySelector returns the first descendant matching a CSS selector
+func querySelector(root *goquery.Selection, selector string) (Element, error) {
+	elements, err := querySelectorAll(root, selector)
+	if err != nil || len(elements) == 0 {
+		return nil, err
+	}
+	return elements[0], nil
+}
+
+// querySelectorAll returns the descendants matching a CSS selector in document order
+func querySelectorAll(root *goquery.Selection, selector string) ([]Element, error) {
+	matcher, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported selector %q: %w", selector, err)
+	}
+
+	found := root.FindMatcher(matcher)
+	elements := make([]Element, 0, found.Length())
+	found.Each(func(_ int, selection *goquery.Selection) {
+		elements = append(elements, &staticElement{selection: selection})
+	})
+	return elements, nil
+}
+
+// writeInnerText collects the visible text of a node, putting block elements on separate lines
+func writeInnerText(text *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		// Переносы и табуляции исходника - обычные пробелы, разделители ставит разметка
+		text.WriteString(sourceSpaces.ReplaceAllString(node.Data, " "))
+		return
+	case html.ElementNode:
+		if hiddenElements[node.Data] {
+			return
+		}
+	}
+
+	block := node.Type == html.ElementNode && blockElements[node.Data]
+	if block {
+		text.WriteString("\n")
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeInnerText(text, child)
+	}
+	if block {
+		text.WriteString("\n")
+	} else if node.Type == html.ElementNode && (node.Data == "td" || node.Data == "th") {
+		text.WriteString("\t")
+	}
+}
+
+// normalizeText collapses spaces inside table cells and lines, keeping the tabs between cells
+// and dropping empty lines like innerText in the browser
+func normalizeText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		cells := strings.Split(line, "\t")
+		for i, cell := range cells {
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		// Разделитель после последней ячейки строки не нужен
+		for len(cells) > 0 && cells[len(cells)-1] == "" {
+			cells = cells[:len(cells)-1]
+		}
+		if len(cells) > 0 {
+			lines = append(lines, strings.Join(cells, "\t"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
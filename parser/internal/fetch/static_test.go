@@ -0,0 +1,46 @@
+package fetch
+
+import "testing"
+
+func TestStaticInnerText(t *testing.T) {
+	const page = `<html><body>
+<table>
+  <tr>
+    <th>Display</th>
+  </tr>
+  <tr>
+    <td>Screen
+        size</td>
+    <td>6.1 <b>inch</b></td>
+  </tr>
+  <tr><td>Resolution</td><td></td><td>2556 x 1179</td></tr>
+</table>
+<p>Compare
+	3 prices</p>
+<script>var hidden = 1;</script>
+</body></html>`
+
+	doc, err := ParseHTML("https://example.com/", 200, []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		expected string
+	}{
+		{"table", "Display\nScreen size\t6.1 inch\nResolution\t\t2556 x 1179"},
+		{"td", "Screen size"},
+		{"p", "Compare 3 prices"},
+		{"body", "Display\nScreen size\t6.1 inch\nResolution\t\t2556 x 1179\nCompare 3 prices"},
+	}
+	for _, test := range tests {
+		text, err := doc.InnerText(test.selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.selector, test.expected, text)
+		}
+	}
+}
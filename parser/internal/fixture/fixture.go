@@ -0,0 +1,198 @@
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/proxy"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// manifestFile is the name of the file mapping page URLs to saved HTML files
+const manifestFile = "manifest.json"
+
+// Page describes one saved HTML page
+type Page struct {
+	URL    string `json:"url"`
+	File   string `json:"file"`
+	Kind   string `json:"page"`
+	ID     string `json:"id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Golden string `json:"golden,omitempty"`
+}
+
+// Manifest maps page URLs to saved HTML files in a fixtures directory
+type Manifest struct {
+	Pages []Page `json:"pages"`
+
+	dir string
+	mu  sync.Mutex
+}
+
+// Load reads the manifest from a fixtures directory.
+// A missing manifest results in an empty one, so recording can start from scratch.
+func Load(dir string) (*Manifest, error) {
+	manifest := &Manifest{dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture manifest: %w", err)
+	}
+
+	for _, page := range manifest.Pages {
+		if !filepath.IsLocal(page.File) {
+			return nil, fmt.Errorf("invalid fixture path: %s", page.File)
+		}
+	}
+
+	return manifest, nil
+}
+
+// Dir returns the fixtures directory
+func (m *Manifest) Dir() string {
+	return m.dir
+}
+
+// Lookup finds the saved page for a URL
+func (m *Manifest) Lookup(url string) (Page, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, page := range m.Pages {
+		if page.URL == url {
+			return page, true
+		}
+	}
+	return Page{}, false
+}
+
+// Install serves document requests of the browser context from saved files.
+// All other requests are aborted, so a crawl never touches the network.
+func (m *Manifest) Install(context playwright.BrowserContext) error {
+	return context.Route("**/*", func(route playwright.Route) {
+		request := route.Request()
+		if request.ResourceType() != "document" {
+			route.Abort()
+			return
+		}
+
+		html, err := m.read(request.URL())
+		if os.IsNotExist(err) {
+			log.Printf("  Fixture not found for %s", request.URL())
+			route.Fulfill(playwright.RouteFulfillOptions{
+				Status: playwright.Int(404),
+				Body:   "fixture not found",
+			})
+			return
+		}
+		if err != nil {
+			log.Printf("  Failed to read fixture for %s: %v", request.URL(), err)
+			route.Abort()
+			return
+		}
+
+		route.Fulfill(playwright.RouteFulfillOptions{
+			Status:      playwright.Int(200),
+			ContentType: playwright.String("text/html; charset=utf-8"),
+			Body:        html,
+		})
+	})
+}
+
+// Fetch returns the saved page of a URL as a static document, for sources in static fetch mode.
+// A URL without a fixture results in a 404 document.
+func (m *Manifest) Fetch(url string, _ *proxy.Proxy) (fetch.Document, error) {
+	html, err := m.read(url)
+	if os.IsNotExist(err) {
+		log.Printf("  Fixture not found for %s", url)
+		return fetch.ParseHTML(url, 404, []byte("fixture not found"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fetch.ParseHTML(url, 200, html)
+}
+
+// read returns the saved HTML of a URL, os.ErrNotExist if the URL has no fixture
+func (m *Manifest) read(url string) ([]byte, error) {
+	page, ok := m.Lookup(url)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return os.ReadFile(filepath.Join(m.dir, page.File))
+}
+
+// Record saves the rendered HTML of a page and adds it to the manifest
+func (m *Manifest) Record(html, url, kind string) error {
+	file := filepath.Join(kind, fileName(url))
+	if err := os.MkdirAll(filepath.Join(m.dir, kind), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(m.dir, file), []byte(html), 0644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recorded := Page{URL: url, File: file, Kind: kind}
+	for i, existing := range m.Pages {
+		if existing.URL == url {
+			recorded.ID, recorded.Title, recorded.Golden = existing.ID, existing.Title, existing.Golden
+			m.Pages[i] = recorded
+			return m.save()
+		}
+	}
+
+	m.Pages = append(m.Pages, recorded)
+	return m.save()
+}
+
+// SetProduct stores the product ID and title a detail page was opened for
+func (m *Manifest) SetProduct(url, id, title string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.Pages {
+		if m.Pages[i].URL == url {
+			m.Pages[i].ID = id
+			m.Pages[i].Title = title
+			return m.save()
+		}
+	}
+	return nil
+}
+
+// save writes the manifest to disk, the caller must hold the lock
+func (m *Manifest) save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.dir, manifestFile), data, 0644)
+}
+
+// fileName creates a safe file name for a page URL
+func fileName(url string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	name = regexp.MustCompile(`[^\w\-.]+`).ReplaceAllString(name, "_")
+	if len(name) > 150 {
+		name = name[:150]
+	}
+	return name + ".html"
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"sort"
+	"sync"
+)
+
+// Extraction fields tracked by coverage metrics
+const (
+	FieldTitle       = "title"
+	FieldPrice       = "price"
+	FieldImage       = "image"
+	FieldFeatures    = "features"
+	FieldDescription = "description"
+)
+
+// FieldCoverage contains hit statistics for one extracted field
+type FieldCoverage struct {
+	Attempts  int            `json:"attempts"`
+	Hits      int            `json:"hits"`
+	Rate      float64        `json:"rate"`
+	Selectors map[string]int `json:"selectors,omitempty"`
+}
+
+// Coverage collects extraction hit-rates during a parser run
+type Coverage struct {
+	mu     sync.Mutex
+	fields map[string]*FieldCoverage
+}
+
+// NewCoverage creates an empty coverage collector
+func NewCoverage() *Coverage {
+	return &Coverage{
+		fields: make(map[string]*FieldCoverage),
+	}
+}
+
+// Record records one extraction attempt of a field.
+// selector is the selector that produced the value, empty if nothing matched.
+func (c *Coverage) Record(field, selector string) {
+	hits := 0
+	if selector != "" {
+		hits = 1
+	}
+	c.RecordN(field, selector, 1, hits)
+}
+
+// RecordN records several extraction attempts of a field at once
+func (c *Coverage) RecordN(field, selector string, attempts, hits int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fc, ok := c.fields[field]
+	if !ok {
+		fc = &FieldCoverage{Selectors: make(map[string]int)}
+		c.fields[field] = fc
+	}
+
+	fc.Attempts += attempts
+	fc.Hits += hits
+	if selector != "" && hits > 0 {
+		fc.Selectors[selector] += hits
+	}
+}
+
+// Snapshot returns a copy of the collected statistics with computed rates
+func (c *Coverage) Snapshot() map[string]FieldCoverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]FieldCoverage, len(c.fields))
+	for field, fc := range c.fields {
+		selectors := make(map[string]int, len(fc.Selectors))
+		for selector, hits := range fc.Selectors {
+			selectors[selector] = hits
+		}
+
+		copied := FieldCoverage{
+			Attempts:  fc.Attempts,
+			Hits:      fc.Hits,
+			Selectors: selectors,
+		}
+		if fc.Attempts > 0 {
+			copied.Rate = float64(fc.Hits) / float64(fc.Attempts)
+		}
+		snapshot[field] = copied
+	}
+
+	return snapshot
+}
+
+// Fields returns the names of recorded fields in stable order
+func (c *Coverage) Fields() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fields := make([]string, 0, len(c.fields))
+	for field := range c.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"pricerunner-parser/internal/config"
+)
+
+// Report contains coverage metrics of a parser run and its health verdict
+type Report struct {
+	Source     string                   `json:"source"`
+	StartedAt  time.Time                `json:"started_at"`
+	FinishedAt time.Time                `json:"finished_at"`
+	Fields     map[string]FieldCoverage `json:"fields"`
+	Degraded   bool                     `json:"degraded"`
+	Problems   []string                 `json:"problems,omitempty"`
+}
+
+// Evaluate builds a report and marks it degraded when a field is below its threshold
+func Evaluate(source string, startedAt time.Time, coverage *Coverage, cfg config.HealthConfig) *Report {
+	report := &Report{
+		Source:     source,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Fields:     coverage.Snapshot(),
+	}
+
+	thresholds := map[string]float64{
+		FieldTitle:    cfg.MinTitleRate,
+		FieldPrice:    cfg.MinPriceRate,
+		FieldImage:    cfg.MinImageRate,
+		FieldFeatures: cfg.MinFeaturesRate,
+	}
+
+	for _, field := range coverage.Fields() {
+		threshold := thresholds[field]
+		fc := report.Fields[field]
+
+		// Слишком мало данных для выводов
+		if threshold <= 0 || fc.Attempts < cfg.MinSamples {
+			continue
+		}
+
+		if fc.Rate < threshold {
+			report.Degraded = true
+			report.Problems = append(report.Problems,
+				fmt.Sprintf("%s coverage %.0f%% is below %.0f%% (%d/%d)", field, fc.Rate*100, threshold*100, fc.Hits, fc.Attempts))
+		}
+	}
+
+	return report
+}
+
+// Alert logs a degraded report and posts it to the configured webhook
+func Alert(report *Report, cfg config.HealthConfig) {
+	if report == nil || !report.Degraded {
+		return
+	}
+
+	log.Printf("🚨 Parser run for %s is DEGRADED, selectors may be broken:", report.Source)
+	for _, problem := range report.Problems {
+		log.Printf("   - %s", problem)
+	}
+
+	if cfg.AlertWebhook == "" {
+		return
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("Failed to encode alert: %v", err)
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(cfg.AlertWebhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Failed to send alert: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("Alert webhook returned status %d", resp.StatusCode)
+	}
+}
@@ -4,30 +4,83 @@ import "time"
 
 // Product represents a product with all its details
 type Product struct {
-	ID          string            `json:"id" db:"id"`
-	Title       string            `json:"title" db:"title"`
-	URL         string            `json:"url" db:"url"`
-	ImageURL    string            `json:"image_url,omitempty" db:"image_url"`
-	ImageLocal  string            `json:"image_local,omitempty" db:"image_local"`
-	Price       *PriceInfo        `json:"price_info,omitempty" db:"-"`
-	Features    map[string]string `json:"features,omitempty" db:"-"`
-	Category    string            `json:"category,omitempty" db:"category"`
-	ExtraImages []ImageInfo       `json:"additional_images,omitempty" db:"-"`
-	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+	ID           string            `json:"id" db:"id"`
+	Title        string            `json:"title" db:"title"`
+	URL          string            `json:"url" db:"url"`
+	ImageURL     string            `json:"image_url,omitempty" db:"image_url"`
+	ImageLocal   string            `json:"image_local,omitempty" db:"image_local"`
+	ImageHash    string            `json:"image_hash,omitempty" db:"image_hash"`
+	Price        *PriceInfo        `json:"price_info,omitempty" db:"-"`
+	Features     map[string]string `json:"features,omitempty" db:"-"`
+	Category     string            `json:"category,omitempty" db:"category"`
+	CategoryPath string            `json:"category_path,omitempty" db:"category_path"`
+	ExtraImages  []ImageInfo       `json:"additional_images,omitempty" db:"-"`
+	Description  string            `json:"description,omitempty" db:"description"`
+	Brand        string            `json:"brand,omitempty" db:"brand"`
+	Model        string            `json:"model,omitempty" db:"model"`
+	GTIN         string            `json:"gtin,omitempty" db:"gtin"`
+	MPN          string            `json:"mpn,omitempty" db:"mpn"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+
+	PriceUpdatedAt  *time.Time `json:"price_updated_at,omitempty" db:"price_updated_at"`
+	PreviousPrice   *PriceInfo `json:"previous_price,omitempty" db:"-"`
+	PreviousPriceAt *time.Time `json:"previous_price_at,omitempty" db:"previous_price_at"`
+
+	// StructuredData contains JSON-LD Product and Offer blocks of the detail page
+	StructuredData []map[string]any `json:"structured_data,omitempty" db:"-"`
+
+	Offers []Offer `json:"offers,omitempty" db:"-"`
+
+	// Image contains metadata of the stored main image
+	Image *ImageInfo `json:"image,omitempty" db:"-"`
+
+	// GroupID identifies the product family, Variant sets the product apart within the family
+	GroupID string   `json:"group_id,omitempty" db:"group_id"`
+	Variant *Variant `json:"variant,omitempty" db:"-"`
+
+	// Lang and Region are the locale of the source the product was parsed from
+	Lang   string `json:"lang,omitempty" db:"-"`
+	Region string `json:"region,omitempty" db:"-"`
+}
+
+// Variant contains the attributes that differ between products of one family
+type Variant struct {
+	Color string `json:"color,omitempty"`
+	Size  string `json:"size,omitempty"`
+}
+
+// Offer contains the price of a product at one merchant
+type Offer struct {
+	Merchant  string    `json:"merchant" db:"merchant"`
+	Price     float64   `json:"price" db:"price"`
+	Currency  string    `json:"currency" db:"currency"`
+	PriceEUR  float64   `json:"price_eur,omitempty" db:"price_eur"`
+	Shipping  string    `json:"shipping,omitempty" db:"shipping"`
+	InStock   bool      `json:"in_stock" db:"in_stock"`
+	URL       string    `json:"url,omitempty" db:"url"`
+	ScrapedAt time.Time `json:"scraped_at" db:"scraped_at"`
 }
 
 // PriceInfo contains price information
 type PriceInfo struct {
-	PriceGBP   string  `json:"price_gbp,omitempty"`
-	PriceEUR   float64 `json:"price_eur,omitempty"`
-	OfferCount string  `json:"offer_count,omitempty"`
+	PriceGBP      string  `json:"price_gbp,omitempty"`
+	PriceEUR      float64 `json:"price_eur,omitempty"`
+	OfferCount    string  `json:"offer_count,omitempty"`
+	PriceOriginal string  `json:"price_original,omitempty"`
+	Currency      string  `json:"currency,omitempty"`
 }
 
-// ImageInfo contains information about additional images
+// ImageInfo contains information about a downloaded image.
+// Local is the object key of the original in the image store.
 type ImageInfo struct {
-	URL   string `json:"url"`
-	Local string `json:"local"`
+	URL    string `json:"url"`
+	Local  string `json:"local"`
+	Hash   string `json:"hash,omitempty"`
+	MIME   string `json:"mime,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Bytes  int64  `json:"bytes,omitempty"`
 }
 
 // BasicProduct represents a product with basic information from list page
@@ -37,6 +90,14 @@ type BasicProduct struct {
 	URL   string `json:"url"`
 }
 
+// PriceUpdate contains a re-scraped price of an already known product
+type PriceUpdate struct {
+	ID        string     `json:"id"`
+	Price     *PriceInfo `json:"price_info"`
+	Offers    []Offer    `json:"offers,omitempty"`
+	CheckedAt time.Time  `json:"checked_at"`
+}
+
 // ProductExists represents a check for product existence
 type ProductExists struct {
 	ID     string
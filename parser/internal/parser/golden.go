@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/fixture"
+	"pricerunner-parser/internal/models"
+	"pricerunner-parser/internal/source"
+)
+
+// GoldenResult contains the outcome of comparing one fixture page with its golden file
+type GoldenResult struct {
+	URL     string   `json:"url"`
+	Page    string   `json:"page"`
+	Golden  string   `json:"golden"`
+	OK      bool     `json:"ok"`
+	Updated bool     `json:"updated,omitempty"`
+	Diff    []string `json:"diff,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// RunGolden replays every fixture page that has a golden file and compares the extraction result with it.
+// With update set the golden files are rewritten with the current result instead.
+func (p *Parser) RunGolden(update bool) ([]GoldenResult, error) {
+	if err := p.initFixtures("replay"); err != nil {
+		return nil, err
+	}
+	defer func() { p.fixtures = nil }()
+
+	// Браузер запускается только для источников в режиме browser
+	p.fetchMode = p.config.Parser.SourceFetchMode()
+	defer p.cleanup()
+
+	var results []GoldenResult
+	for _, page := range p.fixtures.Pages {
+		if page.Golden == "" {
+			continue
+		}
+
+		result := p.checkGolden(page, update)
+		if result.OK {
+			log.Printf("✓ %s (%s)", page.URL, page.Golden)
+		} else {
+			log.Printf("✗ %s (%s): %s %v", page.URL, page.Golden, result.Error, result.Diff)
+		}
+		results = append(results, result)
+	}
+
+	// Пустой манифест означает, что проверять нечего, а не что все совпало
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no fixture pages with golden files in %s", p.fixtures.Dir())
+	}
+
+	return results, nil
+}
+
+// checkGolden extracts data from one fixture page and compares it with the golden file
+func (p *Parser) checkGolden(page fixture.Page, update bool) GoldenResult {
+	result := GoldenResult{URL: page.URL, Page: page.Kind, Golden: page.Golden}
+
+	if !filepath.IsLocal(page.Golden) {
+		result.Error = "invalid golden path"
+		return result
+	}
+
+	actual, err := p.extractFixture(page)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	actualJSON, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	goldenPath := filepath.Join(p.fixtures.Dir(), page.Golden)
+	if update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if err := os.WriteFile(goldenPath, append(actualJSON, '\n'), 0644); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.OK = true
+		result.Updated = true
+		return result
+	}
+
+	expectedJSON, err := os.ReadFile(goldenPath)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read golden file: %v", err)
+		return result
+	}
+
+	var expected, got any
+	if err := json.Unmarshal(expectedJSON, &expected); err != nil {
+		result.Error = fmt.Sprintf("failed to parse golden file: %v", err)
+		return result
+	}
+	json.Unmarshal(actualJSON, &got)
+
+	result.Diff = diffJSON("", expected, got)
+	result.OK = len(result.Diff) == 0
+	return result
+}
+
+// extractFixture runs the source extraction on a replayed page.
+// Timestamps are cleared so the result is stable between runs.
+func (p *Parser) extractFixture(page fixture.Page) (any, error) {
+	doc, err := p.openPage(page.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	return p.extractDocument(doc, page)
+}
+
+// extractDocument runs the source extraction of a fixture page kind on a loaded document
+func (p *Parser) extractDocument(doc fetch.Document, page fixture.Page) (any, error) {
+	switch page.Kind {
+	case source.PageList:
+		return p.source.ExtractProductCards(doc), nil
+	case source.PageDetail:
+		p.source.PrepareDetailPage(doc)
+		product := p.extractProduct(doc, models.BasicProduct{ID: page.ID, Title: page.Title, URL: page.URL})
+		product.CreatedAt = time.Time{}
+		product.UpdatedAt = time.Time{}
+		product.PriceUpdatedAt = nil
+		for i := range product.Offers {
+			product.Offers[i].ScrapedAt = time.Time{}
+		}
+		return product, nil
+	default:
+		return nil, fmt.Errorf("unknown page kind: %s", page.Kind)
+	}
+}
+
+// diffJSON returns the paths of values that differ between two decoded JSON documents
+func diffJSON(path string, expected, actual any) []string {
+	expectedMap, expectedIsMap := expected.(map[string]any)
+	actualMap, actualIsMap := actual.(map[string]any)
+	if expectedIsMap && actualIsMap {
+		keys := make(map[string]bool)
+		for key := range expectedMap {
+			keys[key] = true
+		}
+		for key := range actualMap {
+			keys[key] = true
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		var diff []string
+		for _, key := range sorted {
+			diff = append(diff, diffJSON(joinPath(path, key), expectedMap[key], actualMap[key])...)
+		}
+		return diff
+	}
+
+	expectedList, expectedIsList := expected.([]any)
+	actualList, actualIsList := actual.([]any)
+	if expectedIsList && actualIsList && len(expectedList) == len(actualList) {
+		var diff []string
+		for i := range expectedList {
+			diff = append(diff, diffJSON(fmt.Sprintf("%s[%d]", path, i), expectedList[i], actualList[i])...)
+		}
+		return diff
+	}
+
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+	if path == "" {
+		path = "."
+	}
+	return []string{path}
+}
+
+// joinPath appends a key to a JSON path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/downloader"
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/models"
+	"pricerunner-parser/internal/source"
+	"pricerunner-parser/internal/storage"
+)
+
+// fixturesDir is the fixtures directory of the repository relative to this package
+const fixturesDir = "../../fixtures"
+
+// newGoldenParser creates a parser replaying fixtures in static mode without a browser or network.
+// The configuration is parsed without config.Load, so no output directories appear next to the test.
+func newGoldenParser(t *testing.T, dir string) *Parser {
+	t.Helper()
+
+	data, err := os.ReadFile("../../config.yaml")
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	cfg, err := config.Parse(data)
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	cfg.Parser.Fetch = fetch.ModeStatic
+	cfg.Parser.Fixtures.Dir = dir
+	cfg.Storage.OutputDir = t.TempDir()
+	cfg.Storage.ImagesDir = t.TempDir()
+
+	src, err := source.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+
+	return New(cfg, storage.NewJSONStorage(cfg.Storage.OutputDir), src, downloader.NewFileStore(cfg.Storage.ImagesDir))
+}
+
+// TestGoldenFixtures runs the golden check of the parser on the repository fixtures.
+// Only the static fetch mode is covered, the browser replay needs Playwright browsers.
+// Golden files are rewritten with: go run . -update-golden
+func TestGoldenFixtures(t *testing.T) {
+	p := newGoldenParser(t, fixturesDir)
+	results, err := p.RunGolden(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			t.Errorf("%s (%s): %s", result.URL, result.Golden, result.Error)
+			continue
+		}
+		for _, path := range result.Diff {
+			t.Errorf("%s (%s): %s differs from golden", result.URL, result.Golden, path)
+		}
+		if !result.OK && len(result.Diff) == 0 {
+			t.Errorf("%s (%s): does not match golden", result.URL, result.Golden)
+		}
+	}
+}
+
+// TestGoldenDetailFields pins the detail fixture to known values, so a bad golden update does not go unnoticed
+func TestGoldenDetailFields(t *testing.T) {
+	p := newGoldenParser(t, fixturesDir)
+	if err := p.initFixtures("replay"); err != nil {
+		t.Fatal(err)
+	}
+
+	const url = "https://www.pricerunner.com/pl/1-3200412345/Mobile-Phones/Apple-iPhone-15-128GB-Compare-Prices"
+	page, ok := p.fixtures.Lookup(url)
+	if !ok {
+		t.Fatalf("fixture not found: %s", url)
+	}
+
+	doc, err := p.fixtures.Fetch(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	extracted, err := p.extractDocument(doc, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product := extracted.(*models.Product)
+
+	checks := []struct {
+		field    string
+		got      string
+		expected string
+	}{
+		{"id", product.ID, "1-3200412345"},
+		{"title", product.Title, "Apple iPhone 15 128GB"},
+		{"price", product.Price.PriceOriginal, "£599.00"},
+		{"offer_count", product.Price.OfferCount, "3"},
+		{"brand", product.Brand, "Apple"},
+		{"gtin", product.GTIN, "0195949036125"},
+		{"image_url", product.ImageURL, "https://www.pricerunner.com/product/504x504/3011234567/Apple-iPhone-15-128GB.jpg"},
+		{"feature Design: Colour", product.Features["Design: Colour"], "Black"},
+		{"feature Memory: Storage capacity", product.Features["Memory: Storage capacity"], "128 GB"},
+	}
+	for _, check := range checks {
+		if check.got != check.expected {
+			t.Errorf("%s: expected %q, got %q", check.field, check.expected, check.got)
+		}
+	}
+
+	if len(product.Offers) != 3 {
+		t.Errorf("offers: expected 3, got %d", len(product.Offers))
+	}
+	if len(product.ExtraImages) != 2 {
+		t.Errorf("extra images: expected 2, got %d", len(product.ExtraImages))
+	}
+}
+
+func TestRunGoldenEmptyManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"pages": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := newGoldenParser(t, dir)
+	if _, err := p.RunGolden(false); err == nil {
+		t.Fatal("expected an error for a manifest without golden pages")
+	}
+}
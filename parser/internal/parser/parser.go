@@ -1,17 +1,30 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"pricerunner-parser/internal/category"
 	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/crawlpolicy"
 	"pricerunner-parser/internal/downloader"
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/fixture"
+	"pricerunner-parser/internal/health"
 	"pricerunner-parser/internal/models"
+	"pricerunner-parser/internal/proxy"
+	"pricerunner-parser/internal/retry"
+	"pricerunner-parser/internal/runreport"
+	"pricerunner-parser/internal/source"
 	"pricerunner-parser/internal/storage"
+	"pricerunner-parser/internal/variant"
 
 	"github.com/playwright-community/playwright-go"
 )
@@ -20,29 +33,111 @@ import (
 type Parser struct {
 	config     *config.Config
 	storage    storage.Storage
+	source     source.Source
 	downloader *downloader.ImageDownloader
+	retry      retry.Policy
+	proxies    *proxy.Pool
+	crawl      *crawlpolicy.Policy
+	static     *fetch.StaticFetcher
 	playwright *playwright.Playwright
 	browser    playwright.Browser
+	coverage   *health.Coverage
+	fixtures   *fixture.Manifest
+	// fixturesMode is "replay" or "record" when fixtures are loaded
+	fixturesMode string
+
+	categories *category.Mapper
+	job        Job
+	// fetchMode is the fetch mode of the current job, switched to the browser on fallback
+	fetchMode string
+
+	// reports keeps the run report of every job
+	reports *runreport.Store
+
+	mu         sync.Mutex
+	lastReport *health.Report
+	// blocked contains URLs of the current job skipped by the crawl policy
+	blocked []crawlpolicy.Blocked
+	// run collects the metrics of the current or last job
+	run *runreport.Recorder
+}
+
+// Job describes what a parser run crawls
+type Job struct {
+	// ID identifies the run report of the job, generated if empty
+	ID string `json:"id"`
+	// URL is the first list page, the configured base URL if empty
+	URL string `json:"url"`
+	// Category is the category of the crawled list, used when a page has no breadcrumbs
+	Category string `json:"category"`
 }
 
 // New creates a new parser instance
-func New(cfg *config.Config, store storage.Storage) *Parser {
+func New(cfg *config.Config, store storage.Storage, src source.Source, images downloader.ObjectStore) *Parser {
+	policy := retry.NewPolicy(cfg.Parser.Retry)
+	proxies := proxy.NewPool(cfg.Parser.Proxies)
+	crawl := crawlpolicy.New(cfg.Parser.Crawl)
+
 	return &Parser{
 		config:     cfg,
 		storage:    store,
-		downloader: downloader.NewImageDownloader(cfg.Storage.ImagesDir),
+		source:     src,
+		downloader: downloader.NewImageDownloader(images, policy, proxies, crawl),
+		retry:      policy,
+		proxies:    proxies,
+		crawl:      crawl,
+		// Один HTTP клиент на все страницы, чтобы переиспользовать соединения
+		static:     fetch.NewStaticFetcher(crawl.UserAgent(), cfg.Parser.Browser.Timeout),
+		coverage:   health.NewCoverage(),
+		categories: category.NewMapper(cfg.Categories.Mapping),
+		reports:    runreport.NewStore(filepath.Join(cfg.Storage.OutputDir, "reports")),
+		run:        runreport.NewRecorder("", src.Name(), "", ""),
 	}
 }
 
-// Parse starts the parsing process
-func (p *Parser) Parse() error {
-	// Инициализируем Playwright
-	if err := p.initPlaywright(); err != nil {
-		return fmt.Errorf("failed to init playwright: %w", err)
+// Parse starts the parsing process for a job
+func (p *Parser) Parse(job Job) (err error) {
+	p.job = p.resolveJob(job)
+	log.Printf("Parsing %s (category: %q, job: %s)", p.job.URL, p.job.Category, p.job.ID)
+	p.proxies.Reset()
+
+	run := runreport.NewRecorder(p.job.ID, p.source.Name(), p.job.URL, p.job.Category)
+	p.mu.Lock()
+	p.blocked = nil
+	p.run = run
+	p.mu.Unlock()
+	if err := p.reports.Save(run.Snapshot()); err != nil {
+		log.Printf("Warning: failed to save run report: %v", err)
+	}
+
+	// Собираем метрики покрытия селекторов за запуск
+	p.coverage = health.NewCoverage()
+	startedAt := time.Now()
+	defer func() { p.finishRun(startedAt, err) }()
+
+	// Загружаем сохраненные страницы для офлайн режима или записи
+	if err := p.initFixtures(p.config.Parser.Fixtures.Mode); err != nil {
+		return err
+	}
+
+	// Задание вне разрешенных доменов или запрещенное robots.txt не запускается
+	if !p.offline() {
+		if err := p.checkCrawlPolicy(p.job.URL); err != nil {
+			return err
+		}
+	}
+
+	// Браузер запускается сразу или только при откате со статической загрузки
+	p.fetchMode = p.config.Parser.SourceFetchMode()
+	if p.fetchMode == fetch.ModeBrowser {
+		if err := p.ensureBrowser(); err != nil {
+			return err
+		}
 	}
 	defer p.cleanup()
 
 	var allProducts []models.Product
+	var previousIDs map[string]bool
 	pageNumber := 1
 	maxPages := p.config.Parser.Parsing.MaxPages
 
@@ -55,7 +150,9 @@ func (p *Parser) Parse() error {
 		log.Printf("\n=== Processing page %d ===", pageNumber)
 
 		// Парсим список товаров на странице
+		phaseStarted := time.Now()
 		basicProducts, hasNextPage, err := p.parseProductList(pageNumber)
+		p.run.AddDuration(runreport.PhaseListPages, time.Since(phaseStarted))
 		if err != nil {
 			log.Printf("Failed to parse page %d: %v", pageNumber, err)
 			break
@@ -66,27 +163,52 @@ func (p *Parser) Parse() error {
 			break
 		}
 
+		// Сайт отдает последнюю страницу вместо несуществующей
+		if repeatsPage(previousIDs, basicProducts) {
+			log.Printf("Page %d repeats the products of page %d, stopping", pageNumber, pageNumber-1)
+			break
+		}
+		previousIDs = productIDs(basicProducts)
+		p.run.ListPage(len(basicProducts))
+
 		log.Printf("Found %d products on page %d", len(basicProducts), pageNumber)
 
-		// Фильтруем уже существующие товары
-		newProducts, err := p.filterExistingProducts(basicProducts)
+		// Разделяем новые/устаревшие товары и товары, которым нужно только обновить цену
+		newProducts, refreshProducts, err := p.classifyProducts(basicProducts)
 		if err != nil {
-			log.Printf("Warning: failed to filter existing products: %v", err)
-			newProducts = basicProducts // Продолжаем со всеми товарами
+			log.Printf("Warning: failed to classify existing products: %v", err)
 		}
 
-		log.Printf("New products to process: %d", len(newProducts))
+		log.Printf("New or stale products to process: %d, prices to refresh: %d", len(newProducts), len(refreshProducts))
 
 		// Получаем детальную информацию
+		phaseStarted = time.Now()
 		detailedProducts := p.parseProductDetails(newProducts)
+		p.run.AddDuration(runreport.PhaseDetails, time.Since(phaseStarted))
+
+		// Обновляем цены уже известных товаров
+		phaseStarted = time.Now()
+		priceUpdates := p.refreshPrices(refreshProducts)
+		p.run.AddDuration(runreport.PhasePriceUpdate, time.Since(phaseStarted))
+
+		phaseStarted = time.Now()
+		pageURL := p.source.PageURL(p.job.URL, pageNumber)
+		if len(priceUpdates) > 0 {
+			if err := p.storage.UpdatePrices(priceUpdates); err != nil {
+				log.Printf("Warning: failed to update prices on page %d: %v", pageNumber, err)
+				p.run.Error(pageURL, runreport.StageSave, err)
+			}
+		}
 
 		// Сохраняем данные страницы
 		if len(detailedProducts) > 0 {
 			if err := p.storage.SaveProducts(detailedProducts, pageNumber); err != nil {
 				log.Printf("Warning: failed to save page %d: %v", pageNumber, err)
+				p.run.Error(pageURL, runreport.StageSave, err)
 			}
 			allProducts = append(allProducts, detailedProducts...)
 		}
+		p.run.AddDuration(runreport.PhaseSave, time.Since(phaseStarted))
 
 		log.Printf("=== Page %d completed: %d products processed ===", pageNumber, len(detailedProducts))
 
@@ -104,7 +226,10 @@ func (p *Parser) Parse() error {
 
 	// Сохраняем финальные данные
 	if len(allProducts) > 0 {
-		if err := p.storage.SaveFinalData(allProducts); err != nil {
+		phaseStarted := time.Now()
+		err := p.storage.SaveFinalData(allProducts)
+		p.run.AddDuration(runreport.PhaseSave, time.Since(phaseStarted))
+		if err != nil {
 			return fmt.Errorf("failed to save final data: %w", err)
 		}
 
@@ -136,13 +261,179 @@ func (p *Parser) Parse() error {
 	return nil
 }
 
+// finishRun evaluates selector coverage of the run, raises an alert if it is degraded
+// and saves the run report of the job
+func (p *Parser) finishRun(startedAt time.Time, runErr error) {
+	report := health.Evaluate(p.source.Name(), startedAt, p.coverage, p.config.Health)
+
+	for _, field := range p.coverage.Fields() {
+		fc := report.Fields[field]
+		log.Printf("🔎 %s coverage: %d/%d (%.0f%%)", field, fc.Hits, fc.Attempts, fc.Rate*100)
+	}
+	health.Alert(report, p.config.Health)
+
+	p.mu.Lock()
+	p.lastReport = report
+	p.mu.Unlock()
+
+	runReport := p.run.Finish(runErr, report, p.BlockedURLs(), p.proxies.Stats())
+	if err := p.reports.Save(runReport); err != nil {
+		log.Printf("Warning: failed to save run report: %v", err)
+		return
+	}
+	log.Printf("📝 Run report %s: %d pages, %d new, %d updated, %d failed, %d errors",
+		runReport.JobID, runReport.Pages.Visited, runReport.Products.New, runReport.Products.Updated,
+		runReport.Products.Failed, len(runReport.Errors))
+}
+
+// LastReport returns the health report of the last finished run
+func (p *Parser) LastReport() *health.Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastReport
+}
+
+// RunReport returns the report of a job, the live one for the running job
+func (p *Parser) RunReport(jobID string) (*runreport.Report, error) {
+	p.mu.Lock()
+	run := p.run
+	p.mu.Unlock()
+
+	if jobID != "" && run.JobID() == jobID {
+		return run.Snapshot(), nil
+	}
+	return p.reports.Load(jobID)
+}
+
+// ProxyStats returns request and failure counters of the configured proxies for the current job
+func (p *Parser) ProxyStats() []proxy.Stats {
+	return p.proxies.Stats()
+}
+
+// BlockedURLs returns the URLs of the current job skipped by the crawl policy
+func (p *Parser) BlockedURLs() []crawlpolicy.Blocked {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]crawlpolicy.Blocked(nil), p.blocked...)
+}
+
+// checkCrawlPolicy checks a URL against the crawl policy and records it if it is blocked
+func (p *Parser) checkCrawlPolicy(url string) error {
+	err := p.crawl.Check(url)
+	p.recordBlocked(err)
+	return err
+}
+
+// recordBlocked adds a URL rejected by the crawl policy to the job results
+func (p *Parser) recordBlocked(err error) {
+	var blocked *crawlpolicy.BlockedError
+	if !errors.As(err, &blocked) {
+		return
+	}
+
+	log.Printf("  ⛔ %v", err)
+	p.mu.Lock()
+	p.blocked = append(p.blocked, crawlpolicy.Blocked{URL: blocked.URL, Reason: blocked.Reason, BlockedAt: time.Now()})
+	p.mu.Unlock()
+}
+
+// resolveJob fills the job URL and category from the configuration when they are not set
+func (p *Parser) resolveJob(job Job) Job {
+	if job.ID == "" {
+		job.ID = runreport.NewJobID()
+	}
+	if job.URL == "" {
+		job.URL = p.config.Parser.SourceBaseURL()
+		if job.Category == "" {
+			job.Category = p.config.Parser.SourceCategory()
+		}
+	}
+	if job.Category == "" {
+		job.Category = category.FromURL(job.URL)
+	}
+	return job
+}
+
+// initFixtures loads the fixture manifest for the given mode
+func (p *Parser) initFixtures(mode string) error {
+	p.fixtures = nil
+	switch mode {
+	case "":
+		return nil
+	case "replay", "record":
+	default:
+		return fmt.Errorf("unknown fixtures mode: %s", mode)
+	}
+
+	manifest, err := fixture.Load(p.config.Parser.Fixtures.Dir)
+	if err != nil {
+		return err
+	}
+	p.fixtures = manifest
+	p.fixturesMode = mode
+	log.Printf("Fixtures mode %q using %s (%d pages)", mode, manifest.Dir(), len(manifest.Pages))
+
+	return nil
+}
+
+// offline reports whether pages are replayed from fixtures without network access
+func (p *Parser) offline() bool {
+	return p.fixtures != nil && p.fixturesMode == "replay"
+}
+
+// recordFixture saves the current page to the fixtures directory in record mode
+func (p *Parser) recordFixture(doc fetch.Document, url, kind string) {
+	if p.fixtures == nil || p.fixturesMode != "record" {
+		return
+	}
+	html, err := doc.HTML()
+	if err != nil {
+		log.Printf("  Warning: failed to get content of %s: %v", url, err)
+		return
+	}
+	if err := p.fixtures.Record(html, url, kind); err != nil {
+		log.Printf("  Warning: failed to record fixture for %s: %v", url, err)
+	}
+}
+
 // initPlaywright initializes Playwright browser
 func (p *Parser) initPlaywright() error {
-	pw, err := playwright.Run()
+	pw, browser, err := p.launchBrowser()
 	if err != nil {
 		return err
 	}
 	p.playwright = pw
+	p.browser = browser
+
+	return nil
+}
+
+// ensureBrowser starts the browser of the job if it is not running yet.
+// Sources in static mode install Chromium only when they fall back to the browser.
+func (p *Parser) ensureBrowser() error {
+	if p.browser != nil {
+		return nil
+	}
+
+	if p.config.Parser.SourceFetchMode() == fetch.ModeStatic {
+		log.Println("Installing Playwright browser for fallback...")
+		if err := playwright.Install(&playwright.RunOptions{Browsers: []string{"chromium"}}); err != nil {
+			log.Printf("Warning: Playwright installation failed: %v", err)
+		}
+	}
+
+	if err := p.initPlaywright(); err != nil {
+		return fmt.Errorf("failed to init playwright: %w", err)
+	}
+	return nil
+}
+
+// launchBrowser starts Playwright and launches Chromium
+func (p *Parser) launchBrowser() (*playwright.Playwright, playwright.Browser, error) {
+	pw, err := playwright.Run()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
 		Headless: &p.config.Parser.Browser.Headless,
@@ -153,82 +444,105 @@ func (p *Parser) initPlaywright() error {
 		},
 	})
 	if err != nil {
-		return err
+		pw.Stop()
+		return nil, nil, err
 	}
-	p.browser = browser
 
-	return nil
+	return pw, browser, nil
+}
+
+// newContext creates a browser context with configured viewport and user agent.
+// A nil proxy means a direct connection.
+func (p *Parser) newContext(browser playwright.Browser, selected *proxy.Proxy) (playwright.BrowserContext, error) {
+	options := playwright.BrowserNewContextOptions{
+		Viewport: &playwright.Size{
+			Width:  p.config.Parser.Browser.Viewport.Width,
+			Height: p.config.Parser.Browser.Viewport.Height,
+		},
+		// Браузер представляется тем же именем, по которому проверяется robots.txt
+		UserAgent: playwright.String(p.crawl.UserAgent()),
+	}
+	if selected != nil {
+		options.Proxy = selected.Playwright()
+	}
+	return browser.NewContext(options)
 }
 
 // cleanup closes browser and playwright
 func (p *Parser) cleanup() {
 	if p.browser != nil {
 		p.browser.Close()
+		p.browser = nil
 	}
 	if p.playwright != nil {
 		p.playwright.Stop()
+		p.playwright = nil
 	}
 }
 
 // parseProductList parses the product list from a page
 func (p *Parser) parseProductList(pageNumber int) ([]models.BasicProduct, bool, error) {
-	context, err := p.browser.NewContext(playwright.BrowserNewContextOptions{
-		Viewport: &playwright.Size{
-			Width:  p.config.Parser.Browser.Viewport.Width,
-			Height: p.config.Parser.Browser.Viewport.Height,
-		},
-		UserAgent: &p.config.Parser.Browser.UserAgent,
-	})
-	if err != nil {
-		return nil, false, err
-	}
-	defer context.Close()
-
-	page, err := context.NewPage()
-	if err != nil {
-		return nil, false, err
-	}
-
 	// Переходим на страницу с номером
-	url := fmt.Sprintf("%s?page=%d", p.config.Parser.BaseURL, pageNumber)
+	url := p.source.PageURL(p.job.URL, pageNumber)
 	log.Printf("Loading page: %s", url)
 
-	response, err := page.Goto(url, playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-		Timeout:   playwright.Float(float64(p.config.Parser.Browser.Timeout)),
-	})
+	page, err := p.openPage(url)
 	if err != nil {
+		p.run.Error(url, runreport.StageList, err)
 		return nil, false, err
 	}
+	defer page.Close()
 
 	// Проверяем статус ответа
-	if response.Status() == 404 {
+	if page.Status() == 404 {
 		log.Printf("  Page %d returned 404, no more pages", pageNumber)
 		return nil, false, nil
 	}
 
-	// Ждем загрузки товаров
-	_, err = page.WaitForSelector(p.config.Parser.Selectors.ProductCards, playwright.PageWaitForSelectorOptions{
-		Timeout: playwright.Float(10000),
-	})
-	if err != nil {
-		log.Printf("  No products found on page %d", pageNumber)
-		return nil, false, nil
-	}
+	if browserPage := page.Browser(); browserPage != nil {
+		// Ждем загрузки товаров
+		_, err = browserPage.WaitForSelector(p.source.ProductCardsSelector(), playwright.PageWaitForSelectorOptions{
+			Timeout: playwright.Float(10000),
+		})
+		if err != nil {
+			log.Printf("  No products found on page %d", pageNumber)
+			return nil, false, nil
+		}
 
-	// Прокручиваем страницу для загрузки всех товаров
-	p.scrollPage(page)
+		// Прокручиваем страницу для загрузки всех товаров
+		p.scrollPage(browserPage)
+	}
+	p.source.PrepareListPage(page)
+	p.recordFixture(page, url, source.PageList)
 
 	// Извлекаем товары
-	products := p.extractProductCards(page)
+	products := p.source.ExtractProductCards(page)
+
+	// Карточки рисуются скриптами: остаток задания выполняется в браузере
+	if len(products) == 0 && p.fetchMode == fetch.ModeStatic {
+		log.Printf("  No product cards in static HTML of page %d, falling back to the browser", pageNumber)
+		p.fetchMode = fetch.ModeBrowser
+		return p.parseProductList(pageNumber)
+	}
+
+	// Доля карточек, из которых удалось извлечь название и ссылку
+	if cards, err := page.QuerySelectorAll(p.source.ProductCardsSelector()); err == nil {
+		p.coverage.RecordN(health.FieldTitle, p.source.ProductCardsSelector(), len(cards), len(products))
+	}
 
 	if len(products) == 0 {
 		log.Printf("  No products extracted from page %d", pageNumber)
 		return nil, false, nil
 	}
 
-	// Определяем есть ли следующая страница по количеству товаров
-	hasNextPage := p.hasNextPage(len(products), pageNumber)
+	// Определяем есть ли следующая страница
+	hasNextPage := p.source.HasNextPage(page, len(products), pageNumber)
+
+	// Если достигли максимального числа страниц из конфига
+	if hasNextPage && p.config.Parser.Parsing.MaxPages > 0 && pageNumber >= p.config.Parser.Parsing.MaxPages {
+		log.Printf("    Reached max pages limit (%d)", p.config.Parser.Parsing.MaxPages)
+		hasNextPage = false
+	}
 
 	return products, hasNextPage, nil
 }
@@ -285,94 +599,152 @@ func (p *Parser) scrollPage(page playwright.Page) {
 	time.Sleep(time.Second)
 }
 
-// extractProductCards extracts product information from page
-func (p *Parser) extractProductCards(page playwright.Page) []models.BasicProduct {
-	cards, err := page.QuerySelectorAll(p.config.Parser.Selectors.ProductCards)
+// classifyProducts splits products into new or stale ones that need full parsing
+// and known ones that only need a price refresh
+func (p *Parser) classifyProducts(products []models.BasicProduct) ([]models.BasicProduct, []models.BasicProduct, error) {
+	updateTimes, err := p.storage.GetProductUpdateTimes()
 	if err != nil {
-		log.Printf("Error querying product cards: %v", err)
-		return nil
+		return products, nil, err // Парсим все товары полностью если не можем проверить
 	}
 
-	var products []models.BasicProduct
+	staleAfter := time.Duration(p.config.Parser.Parsing.StaleAfterHours) * time.Hour
 
-	for _, card := range cards {
-		title, err := card.GetAttribute("title")
-		if err != nil || title == "" {
-			continue
+	var fullParse, refresh []models.BasicProduct
+	for _, product := range products {
+		updatedAt, exists := updateTimes[product.ID]
+		if exists {
+			p.run.MarkExisting(product.ID)
 		}
-
-		href, err := card.GetAttribute("href")
-		if err != nil || href == "" {
-			continue
+		switch {
+		case !exists:
+			fullParse = append(fullParse, product)
+		case staleAfter > 0 && time.Since(updatedAt) > staleAfter:
+			fullParse = append(fullParse, product)
+		case p.config.Parser.Parsing.RefreshPrices:
+			refresh = append(refresh, product)
 		}
+	}
+
+	return fullParse, refresh, nil
+}
+
+// refreshPrices re-scrapes only the current price of known products
+func (p *Parser) refreshPrices(products []models.BasicProduct) []models.PriceUpdate {
+	var updates []models.PriceUpdate
+
+	for i, basicProduct := range products {
+		log.Printf("[%d/%d] Refreshing price: %s (ID: %s)", i+1, len(products),
+			truncateString(basicProduct.Title, 50), basicProduct.ID)
 
-		// Извлекаем ID из URL
-		re := regexp.MustCompile(`/pl/(\d+-\d+)/`)
-		matches := re.FindStringSubmatch(href)
-		if len(matches) < 2 {
+		update, err := p.refreshPrice(basicProduct)
+		if err != nil {
+			log.Printf("  ✗ Failed to refresh price: %v", err)
+			p.run.ProductFailed(basicProduct.URL, runreport.StagePrice, err)
 			continue
 		}
 
-		productID := matches[1]
-
-		// Формируем полный URL
-		fullURL := href
-		if !strings.HasPrefix(href, "http") {
-			fullURL = "https://www.pricerunner.com" + href
+		if update.Price == nil {
+			log.Printf("  ⚠ No price found, keeping the stored one")
+		} else {
+			log.Printf("  ✓ Price: €%.2f", update.Price.PriceEUR)
+			updates = append(updates, *update)
+			p.run.PriceRefreshed()
 		}
 
-		products = append(products, models.BasicProduct{
-			ID:    productID,
-			Title: title,
-			URL:   fullURL,
-		})
+		// Пауза между запросами
+		time.Sleep(time.Duration(p.config.Parser.Parsing.DelayBetweenRequests) * time.Millisecond)
 	}
 
-	return products
+	return updates
 }
 
-// hasNextPage checks if there's a next page based on product count
-func (p *Parser) hasNextPage(productsCount int, pageNumber int) bool {
-	log.Printf("  Checking if page %d has next page...", pageNumber)
+// refreshPrice loads a detail page without scrolling and reads the price only
+func (p *Parser) refreshPrice(basic models.BasicProduct) (*models.PriceUpdate, error) {
+	page, err := p.openPage(basic.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer page.Close()
 
-	// Если на странице меньше товаров чем обычно, скорее всего это последняя страница
-	expectedProductsPerPage := 48
+	priceText, offerCount := p.source.ExtractPrice(page)
+	now := time.Now()
+	offers := p.buildOffers(p.source.ExtractOffers(page), now)
 
-	if productsCount < expectedProductsPerPage {
-		log.Printf("    Found only %d products (expected ~%d), likely last page", productsCount, expectedProductsPerPage)
-		return false
+	return &models.PriceUpdate{
+		ID:        basic.ID,
+		Price:     p.buildPriceInfo(priceText, offerCountOrLen(offerCount, offers)),
+		Offers:    offers,
+		CheckedAt: now,
+	}, nil
+}
+
+// openPage loads the URL with the fetcher of the current mode, retrying timeouts, network errors,
+// 429 and 5xx with backoff and the next proxy of the pool. The document must be closed.
+func (p *Parser) openPage(url string) (fetch.Document, error) {
+	if !p.offline() {
+		if err := p.checkCrawlPolicy(url); err != nil {
+			return nil, err
+		}
 	}
 
-	// Если достигли максимального числа страниц из конфига
-	if p.config.Parser.Parsing.MaxPages > 0 && pageNumber >= p.config.Parser.Parsing.MaxPages {
-		log.Printf("    Reached max pages limit (%d)", p.config.Parser.Parsing.MaxPages)
-		return false
+	fetcher, err := p.fetcher()
+	if err != nil {
+		return nil, err
 	}
 
-	log.Printf("    ✓ Page %d has %d products, assuming next page exists", pageNumber, productsCount)
-	return true
-}
+	var doc fetch.Document
+	err = p.retry.Do("page load", func(attempt int) error {
+		// Сохраненные страницы отдаются без сети и прокси
+		var selected *proxy.Proxy
+		if !p.offline() {
+			p.crawl.Wait(url)
+			selected = p.proxies.Next()
+		}
 
-// filterExistingProducts filters out products that already exist
-func (p *Parser) filterExistingProducts(products []models.BasicProduct) ([]models.BasicProduct, error) {
-	existingIDs, err := p.storage.GetExistingProducts()
+		var err error
+		doc, err = fetcher.Fetch(url, selected)
+		p.proxies.Report(selected, err)
+		return err
+	})
 	if err != nil {
-		return products, err // Возвращаем все товары если не можем проверить
+		return nil, err
 	}
 
-	existingSet := make(map[string]bool)
-	for _, id := range existingIDs {
-		existingSet[id] = true
+	p.run.PageLoaded(doc.Size())
+	return doc, nil
+}
+
+// fetcher returns the page fetcher of the current job mode, starting the browser when it is needed
+func (p *Parser) fetcher() (fetch.Fetcher, error) {
+	if p.fetchMode == fetch.ModeStatic {
+		// В офлайн режиме страницы читаются из сохраненных файлов
+		if p.offline() {
+			return p.fixtures, nil
+		}
+		return p.static, nil
 	}
 
-	var newProducts []models.BasicProduct
-	for _, product := range products {
-		if !existingSet[product.ID] {
-			newProducts = append(newProducts, product)
+	if err := p.ensureBrowser(); err != nil {
+		return nil, err
+	}
+	return fetch.NewBrowserFetcher(p.openContext, p.config.Parser.Browser.Timeout), nil
+}
+
+// openContext creates a browser context, serving fixtures in offline mode
+func (p *Parser) openContext(selected *proxy.Proxy) (playwright.BrowserContext, error) {
+	context, err := p.newContext(p.browser, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.offline() {
+		if err := p.fixtures.Install(context); err != nil {
+			context.Close()
+			return nil, err
 		}
 	}
 
-	return newProducts, nil
+	return context, nil
 }
 
 // parseProductDetails gets detailed information for each product
@@ -386,8 +758,10 @@ func (p *Parser) parseProductDetails(products []models.BasicProduct) []models.Pr
 		details, err := p.parseProductDetail(basicProduct)
 		if err != nil {
 			log.Printf("  ✗ Failed to parse details: %v", err)
+			p.run.ProductFailed(basicProduct.URL, runreport.StageDetail, err)
 			continue
 		}
+		p.run.ProductParsed(basicProduct.ID)
 
 		detailedProducts = append(detailedProducts, *details)
 
@@ -424,530 +798,216 @@ func (p *Parser) parseProductDetails(products []models.BasicProduct) []models.Pr
 
 // parseProductDetail parses detailed information for a single product
 func (p *Parser) parseProductDetail(basic models.BasicProduct) (*models.Product, error) {
-	context, err := p.browser.NewContext(playwright.BrowserNewContextOptions{
-		Viewport: &playwright.Size{
-			Width:  p.config.Parser.Browser.Viewport.Width,
-			Height: p.config.Parser.Browser.Viewport.Height,
-		},
-		UserAgent: &p.config.Parser.Browser.UserAgent,
-	})
+	// Переходим на страницу товара
+	doc, err := p.openPage(basic.URL)
 	if err != nil {
 		return nil, err
 	}
-	defer context.Close()
+	defer doc.Close()
 
-	page, err := context.NewPage()
-	if err != nil {
-		return nil, err
+	// Минимальная пауза для начальной загрузки
+	if doc.Browser() != nil {
+		time.Sleep(500 * time.Millisecond)
 	}
 
-	// Переходим на страницу товара
-	if _, err := page.Goto(basic.URL, playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-		Timeout:   playwright.Float(float64(p.config.Parser.Browser.Timeout)),
-	}); err != nil {
-		return nil, err
-	}
+	// Прокрутка для загрузки всего контента
+	p.source.PrepareDetailPage(doc)
 
-	// Минимальная пауза для начальной загрузки
-	time.Sleep(500 * time.Millisecond)
+	if p.fixtures != nil && p.fixturesMode == "record" {
+		p.recordFixture(doc, basic.URL, source.PageDetail)
+		p.fixtures.SetProduct(basic.URL, basic.ID, basic.Title)
+	}
 
-	// Универсальная прокрутка для загрузки всего контента
-	p.scrollToFeatures(page)
+	return p.extractProduct(doc, basic), nil
+}
 
+// extractProduct builds a product from a loaded detail page
+func (p *Parser) extractProduct(page fetch.Document, basic models.BasicProduct) *models.Product {
 	// Создаем объект продукта
+	now := time.Now()
 	product := &models.Product{
-		ID:        basic.ID,
-		Title:     basic.Title,
-		URL:       basic.URL,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:             basic.ID,
+		Title:          basic.Title,
+		URL:            basic.URL,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		PriceUpdatedAt: &now,
 	}
 
 	// Парсим все данные
-	product.Price = p.parsePrice(page)
-	p.parseMainImage(page, product)
-	product.ExtraImages = p.parseAdditionalImages(page, basic.ID)
-	product.Features = p.parseFeatures(page)
-
-	return product, nil
+	detail := p.source.ExtractDetail(page)
+	for _, field := range []string{source.FieldPrice, source.FieldImage, source.FieldFeatures, source.FieldDescription} {
+		p.coverage.Record(field, detail.Matched[field])
+	}
+	product.Offers = p.buildOffers(p.source.ExtractOffers(page), now)
+	product.Price = p.buildPriceInfo(detail.PriceText, offerCountOrLen(detail.OfferCount, product.Offers))
+	product.Features = detail.Features
+	product.Category, product.CategoryPath = p.categories.Resolve(p.job.Category, detail.Breadcrumbs, basic.Title)
+	family := variant.Extract(basic.Title, product.Category, detail.Features)
+	product.GroupID = family.GroupID
+	if !family.IsEmpty() {
+		product.Variant = &models.Variant{Color: family.Color, Size: family.Size}
+	}
+	product.Lang, product.Region = p.config.Parser.SourceLocale()
+	product.Description = detail.Description
+	product.Brand = detail.Brand
+	product.Model = detail.Model
+	product.GTIN = detail.GTIN
+	product.MPN = detail.MPN
+	product.StructuredData = detail.StructuredData
+	p.downloadMainImage(detail.ImageURL, product)
+	product.ExtraImages = p.downloadAdditionalImages(detail.ExtraImages)
+
+	return product
 }
 
-// scrollToFeatures улучшенная прокрутка для загрузки всего контента
-func (p *Parser) scrollToFeatures(page playwright.Page) {
-	log.Printf("  Loading all page content...")
-
-	// Стратегия: быстро прокрутить всю страницу для загрузки контента
-
-	// 1. Прокручиваем до конца страницы
-	page.Evaluate("window.scrollTo(0, document.body.scrollHeight)")
-	time.Sleep(1 * time.Second)
-
-	// 2. Проверяем есть ли таблицы
-	tablesCount := p.checkTablesCountQuick(page)
-	log.Printf("    After full scroll: %d tables found", tablesCount)
-
-	// 3. Если таблиц мало, делаем дополнительные прокрутки
-	if tablesCount < 2 {
-		log.Printf("    Need more content, doing additional scrolling...")
-
-		// Прокручиваем к разным частям страницы
-		positions := []string{
-			"document.body.scrollHeight * 0.75", // 75%
-			"document.body.scrollHeight * 0.5",  // 50%
-			"document.body.scrollHeight * 0.25", // 25%
-		}
-
-		for _, pos := range positions {
-			page.Evaluate(fmt.Sprintf("window.scrollTo(0, %s)", pos))
-			time.Sleep(500 * time.Millisecond)
-		}
-
-		// Финальная прокрутка к концу
-		page.Evaluate("window.scrollTo(0, document.body.scrollHeight)")
-		time.Sleep(1 * time.Second)
-	}
-
-	// 4. Позиционируемся в середине для парсинга
-	page.Evaluate("window.scrollTo(0, document.body.scrollHeight / 2)")
-	time.Sleep(500 * time.Millisecond)
-
-	finalCount := p.checkTablesCountQuick(page)
-	log.Printf("    Final: %d tables loaded", finalCount)
-}
-
-// checkTablesCountQuick быстрая проверка количества таблиц
-func (p *Parser) checkTablesCountQuick(page playwright.Page) int {
-	// Пробуем основной селектор
-	tables, err := page.QuerySelectorAll(p.config.Parser.Selectors.FeatureTables)
-	if err == nil && len(tables) > 0 {
-		return len(tables)
+// buildPriceInfo converts a price shown by the source to EUR
+func (p *Parser) buildPriceInfo(priceText, offerCount string) *models.PriceInfo {
+	if priceText == "" {
+		return nil
 	}
 
-	// Пробуем альтернативные селекторы
-	altSelectors := []string{
-		"table.pr-1regpt0-Table-table",
-		"div[class*='Table'] table",
-		"table",
+	currency := p.source.Currency()
+	priceInfo := &models.PriceInfo{
+		PriceOriginal: priceText,
+		Currency:      currency,
+		OfferCount:    offerCount,
+		PriceEUR:      parsePriceAmount(priceText) * p.config.Currency.RateToEUR(currency),
 	}
-
-	maxCount := 0
-	for _, selector := range altSelectors {
-		tables, err := page.QuerySelectorAll(selector)
-		if err == nil && len(tables) > maxCount {
-			maxCount = len(tables)
-		}
+	if currency == "GBP" {
+		priceInfo.PriceGBP = priceText
 	}
 
-	return maxCount
+	return priceInfo
 }
 
-// parsePrice parses price using the improved selector for second span
-func (p *Parser) parsePrice(page playwright.Page) *models.PriceInfo {
-	log.Printf("    Looking for price (second span)...")
-
-	// Способ 1: Ищем все span'ы с нужным классом и берем второй
-	priceElements, err := page.QuerySelectorAll("span.pr-1fcg5be")
-	if err != nil || len(priceElements) < 2 {
-		log.Printf("    Not enough price elements found (need 2, got %d)", len(priceElements))
-		return p.parsePriceFallback(page)
-	}
-
-	// Берем второй элемент (индекс 1)
-	secondPriceElement := priceElements[1]
-
-	priceText, err := secondPriceElement.InnerText()
-	if err != nil || priceText == "" {
-		log.Printf("    Failed to get text from second price element")
-		return p.parsePriceFallback(page)
-	}
-
-	priceText = strings.TrimSpace(priceText)
-	log.Printf("    Found price (second span): %s", priceText)
+// buildOffers converts raw merchant offers and their prices to EUR
+func (p *Parser) buildOffers(rawOffers []source.Offer, scrapedAt time.Time) []models.Offer {
+	var offers []models.Offer
+	for _, raw := range rawOffers {
+		currency := strings.ToUpper(raw.Currency)
+		if currency == "" {
+			currency = p.source.Currency()
+		}
 
-	// Конвертируем в EUR
-	priceEUR := p.convertGBPToEUR(priceText)
+		// Точная цена из структурированных данных не проходит через разбор текста
+		price := raw.Price
+		if price <= 0 {
+			price = parsePriceAmount(raw.PriceText)
+		}
+		if price <= 0 {
+			continue
+		}
 
-	return &models.PriceInfo{
-		PriceGBP: priceText,
-		PriceEUR: priceEUR,
+		offers = append(offers, models.Offer{
+			Merchant:  raw.Merchant,
+			Price:     price,
+			Currency:  currency,
+			PriceEUR:  price * p.config.Currency.RateToEUR(currency),
+			Shipping:  raw.Shipping,
+			InStock:   raw.InStock,
+			URL:       raw.URL,
+			ScrapedAt: scrapedAt,
+		})
 	}
+	return offers
 }
 
-// parsePriceFallback - альтернативные способы поиска цены
-func (p *Parser) parsePriceFallback(page playwright.Page) *models.PriceInfo {
-	log.Printf("    Trying fallback price selectors...")
-
-	// Способ 2: Ищем по тексту "Lowest Price Now"
-	nowPriceSelector := `//p[contains(text(), "Lowest Price Now")]/following-sibling::span[@class="pr-1fcg5be"]`
-	if priceElement, err := page.QuerySelector(nowPriceSelector); err == nil && priceElement != nil {
-		if priceText, err := priceElement.InnerText(); err == nil && priceText != "" {
-			log.Printf("    Found price via 'Lowest Price Now': %s", strings.TrimSpace(priceText))
-			return &models.PriceInfo{
-				PriceGBP: strings.TrimSpace(priceText),
-				PriceEUR: p.convertGBPToEUR(priceText),
-			}
-		}
-	}
-
-	// Способ 3: Ищем по структуре DOM - второй div с классом pr-i5pc8s
-	structureSelector := `div.pr-1ymxntz div.pr-i5pc8s:nth-child(2) span.pr-1fcg5be`
-	if priceElement, err := page.QuerySelector(structureSelector); err == nil && priceElement != nil {
-		if priceText, err := priceElement.InnerText(); err == nil && priceText != "" {
-			log.Printf("    Found price via structure selector: %s", strings.TrimSpace(priceText))
-			return &models.PriceInfo{
-				PriceGBP: strings.TrimSpace(priceText),
-				PriceEUR: p.convertGBPToEUR(priceText),
-			}
-		}
+// offerCountOrLen returns the offer count shown on the page or the number of scraped offers
+func offerCountOrLen(offerCount string, offers []models.Offer) string {
+	if offerCount == "" && len(offers) > 0 {
+		return strconv.Itoa(len(offers))
 	}
-
-	log.Printf("    ⚠ No price found with any method")
-	return nil
+	return offerCount
 }
 
-// convertGBPToEUR converts GBP price to EUR
-func (p *Parser) convertGBPToEUR(priceStr string) float64 {
-	// Удаляем символы валюты и пробелы
-	re := regexp.MustCompile(`[£€\s,]`)
+// parsePriceAmount extracts the numeric amount from a price string like "£1,299.00" or "1.299,00 €"
+func parsePriceAmount(priceStr string) float64 {
+	// Оставляем только цифры и разделители
+	re := regexp.MustCompile(`[^\d.,]`)
 	cleanPrice := re.ReplaceAllString(priceStr, "")
 
+	// Последний разделитель с одной или двумя цифрами после него считаем десятичным: "€12,5", "£1,299.00"
+	lastSep := strings.LastIndexAny(cleanPrice, ".,")
+	if decimals := len(cleanPrice) - lastSep - 1; lastSep >= 0 && (decimals == 1 || decimals == 2) {
+		cleanPrice = strings.NewReplacer(".", "", ",", "").Replace(cleanPrice[:lastSep]) + "." + cleanPrice[lastSep+1:]
+	} else {
+		cleanPrice = strings.NewReplacer(".", "", ",", "").Replace(cleanPrice)
+	}
+
 	price, err := strconv.ParseFloat(cleanPrice, 64)
 	if err != nil {
 		return 0
 	}
 
-	return price * p.config.Currency.GBPToEUR
+	return price
 }
 
-// parseMainImage parses the main product image
-func (p *Parser) parseMainImage(page playwright.Page, product *models.Product) {
-	log.Printf("  Looking for images...")
-
-	selectors := []string{
-		p.config.Parser.Selectors.MainImage,
-		"picture.pr-lpjxdi source[type='image/jpeg']",
-		"img[itemprop='image']",
-		"div.pr-15dcama img",
-	}
-
-	var imageURL string
-
-	for _, selector := range selectors {
-		if strings.Contains(selector, "source") {
-			source, err := page.QuerySelector(selector)
-			if err == nil && source != nil {
-				srcset, _ := source.GetAttribute("srcset")
-				if srcset != "" {
-					urls := strings.Split(srcset, ",")
-					if len(urls) > 0 {
-						imageURL = strings.TrimSpace(strings.Fields(urls[len(urls)-1])[0])
-						break
-					}
-				}
-			}
-		} else {
-			img, err := page.QuerySelector(selector)
-			if err == nil && img != nil {
-				srcset, _ := img.GetAttribute("srcset")
-				if srcset != "" {
-					urls := strings.Split(srcset, ",")
-					if len(urls) > 0 {
-						imageURL = strings.TrimSpace(strings.Fields(urls[len(urls)-1])[0])
-						break
-					}
-				} else {
-					src, _ := img.GetAttribute("src")
-					if src != "" && !strings.HasPrefix(src, "data:") {
-						imageURL = src
-						break
-					}
-				}
-			}
-		}
-	}
-
+// downloadMainImage downloads the main product image
+func (p *Parser) downloadMainImage(imageURL string, product *models.Product) {
 	product.ImageURL = imageURL
-
-	if imageURL != "" {
-		localPath, err := p.downloader.DownloadImage(imageURL, product.ID)
-		if err != nil {
-			log.Printf("    ✗ Failed to download main image: %v", err)
-		} else {
-			product.ImageLocal = localPath
-		}
-	} else {
-		log.Printf("    ⚠ Main image not found")
+	if imageURL == "" || p.offline() {
+		return
 	}
-}
-
-// parseAdditionalImages parses additional product images
-func (p *Parser) parseAdditionalImages(page playwright.Page, productID string) []models.ImageInfo {
-	log.Printf("  Looking for additional images...")
 
-	thumbnails, err := page.QuerySelectorAll(p.config.Parser.Selectors.AdditionalImages)
+	image, err := p.downloader.DownloadImage(imageURL)
 	if err != nil {
-		return nil
-	}
+		log.Printf("    ✗ Failed to download main image: %v", err)
+		p.recordBlocked(err)
+		p.run.Error(imageURL, runreport.StageImage, err)
+		return
+	}
+	p.run.ImageDownloaded(image.Bytes)
+	product.Image = image
+	product.ImageLocal = image.Local
+	product.ImageHash = image.Hash
+}
 
+// downloadAdditionalImages downloads additional product images
+func (p *Parser) downloadAdditionalImages(imageURLs []string) []models.ImageInfo {
 	var additionalImages []models.ImageInfo
-	maxImages := 3 // Ограничиваем для скорости
 
-	for i, thumb := range thumbnails {
-		if i >= maxImages {
-			break
-		}
-
-		src, err := thumb.GetAttribute("src")
-		if err != nil || src == "" || strings.HasPrefix(src, "data:") {
+	for i, imageURL := range imageURLs {
+		// В офлайн режиме изображения не скачиваются
+		if p.offline() {
+			additionalImages = append(additionalImages, models.ImageInfo{URL: imageURL})
 			continue
 		}
 
-		// Заменяем размер на больший
-		re := regexp.MustCompile(`/dim/dim/`)
-		largeURL := re.ReplaceAllString(src, "/504x504/")
-
-		localPath, err := p.downloader.DownloadImage(largeURL, fmt.Sprintf("%s_extra_%d", productID, i+1))
+		image, err := p.downloader.DownloadImage(imageURL)
 		if err != nil {
 			log.Printf("    ✗ Failed to download additional image %d: %v", i+1, err)
+			p.recordBlocked(err)
+			p.run.Error(imageURL, runreport.StageImage, err)
 			continue
 		}
+		p.run.ImageDownloaded(image.Bytes)
 
-		additionalImages = append(additionalImages, models.ImageInfo{
-			URL:   largeURL,
-			Local: localPath,
-		})
+		additionalImages = append(additionalImages, *image)
 	}
 
 	return additionalImages
 }
 
-// parseFeatures парсинг таблицы с множественными заголовками внутри
-func (p *Parser) parseFeatures(page playwright.Page) map[string]string {
-	log.Printf("  Extracting features...")
-
-	features := make(map[string]string)
-
-	// Пробуем разные селекторы для таблиц
-	tableSelectors := []string{
-		p.config.Parser.Selectors.FeatureTables, // Основной
-		"table.pr-1regpt0-Table-table",          // Альтернативный
-		"div[class*='Table'] table",             // По части класса
-		"table",                                 // Любые таблицы
-	}
-
-	var tables []playwright.ElementHandle
-
-	for _, selector := range tableSelectors {
-		foundTables, err := page.QuerySelectorAll(selector)
-		if err == nil && len(foundTables) > 0 {
-			tables = foundTables
-			log.Printf("    Found %d tables with selector: %s", len(foundTables), selector)
-			break
-		}
-	}
-
-	if len(tables) == 0 {
-		log.Printf("    ⚠ No feature tables found with any selector")
-		return features
-	}
-
-	// Парсим каждую таблицу
-	for tableIdx, table := range tables {
-		log.Printf("    Processing table %d/%d", tableIdx+1, len(tables))
-
-		// Парсим таблицу с множественными заголовками
-		tableFeatures := p.parseTableWithMultipleHeaders(table)
-
-		// Объединяем с общим списком
-		for key, value := range tableFeatures {
-			features[key] = value
-		}
-	}
-
-	if len(features) > 0 {
-		log.Printf("    ✓ Total features found: %d", len(features))
-	} else {
-		log.Printf("    ⚠ No valid features extracted")
-	}
-
-	return features
-}
-
-// Исправляем parseTableWithMultipleHeaders - убираем неиспользуемую переменную
-func (p *Parser) parseTableWithMultipleHeaders(table playwright.ElementHandle) map[string]string {
-	features := make(map[string]string)
-
-	// Получаем все строки таблицы (и thead, и tbody)
-	allRows, err := table.QuerySelectorAll("tr")
-	if err != nil {
-		return features
-	}
-
-	currentCategory := "General"
-
-	for _, row := range allRows { // Убрали i, _
-		// Проверяем является ли строка заголовком
-		if p.isHeaderRow(row) {
-			// Это строка заголовка - извлекаем категорию
-			category := p.extractCategoryFromHeaderRow(row)
-			if category != "" {
-				currentCategory = category
-				log.Printf("      Found category header: '%s'", currentCategory)
-			}
-			continue
-		}
-
-		// Это строка данных - парсим характеристики
-		featureName, featureValue := p.extractFeatureFromRow(row)
-		if featureName != "" && featureValue != "" {
-			// Добавляем категорию к названию если она значимая
-			key := featureName
-			if currentCategory != "General" && currentCategory != "" {
-				key = fmt.Sprintf("%s: %s", currentCategory, featureName)
-			}
-
-			features[key] = featureValue
-		}
-	}
-
-	log.Printf("      Extracted %d features from table", len(features))
-	return features
-}
-
-// isHeaderRow проверяет является ли строка заголовком
-func (p *Parser) isHeaderRow(row playwright.ElementHandle) bool {
-	// Способ 1: Проверяем родительский элемент (thead)
-	script := "\n\t\t\t\tlet row = arguments[0];\n\t\t\t\tlet parent = row.parentElement;\n\t\t\t\treturn parent && parent.tagName === 'THEAD';\n\t\t"
-	if result, err := row.Evaluate(script); err == nil {
-		if isInThead, ok := result.(bool); ok && isInThead {
-			return true
-		}
-	}
-
-	// Способ 2: Проверяем наличие th вместо td
-	ths, err := row.QuerySelectorAll("th")
-	if err == nil && len(ths) > 0 {
-		return true
-	}
-
-	// Способ 3: Проверяем CSS класс или data-атрибуты
-	class, _ := row.GetAttribute("class")
-	dataKind, _ := row.GetAttribute("data-kind")
-
-	if strings.Contains(class, "heading") || strings.Contains(class, "header") ||
-		dataKind == "heading" || dataKind == "header" {
-		return true
-	}
-
-	// Способ 4: Проверяем ячейки с data-kind="heading"
-	cells, err := row.QuerySelectorAll("td, th")
-	if err == nil {
-		for _, cell := range cells {
-			if cellDataKind, _ := cell.GetAttribute("data-kind"); cellDataKind == "heading" {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-// extractCategoryFromHeaderRow извлекает название категории из строки заголовка
-func (p *Parser) extractCategoryFromHeaderRow(row playwright.ElementHandle) string {
-	// Ищем div.pr-f8aw3g с текстом
-	if categoryDiv, err := row.QuerySelector("div.pr-f8aw3g"); err == nil && categoryDiv != nil {
-		if categoryText, err := categoryDiv.InnerText(); err == nil && categoryText != "" {
-			cleaned := strings.TrimSpace(categoryText)
-			if p.isValidCategory(cleaned) {
-				return cleaned
-			}
-		}
-	}
-
-	// Альтернативно - ищем в th
-	if th, err := row.QuerySelector("th"); err == nil && th != nil {
-		if categoryText, err := th.InnerText(); err == nil && categoryText != "" {
-			cleaned := strings.TrimSpace(categoryText)
-			if p.isValidCategory(cleaned) {
-				return cleaned
-			}
-		}
-	}
-
-	// Ищем в любой ячейке
-	cells, err := row.QuerySelectorAll("td, th")
-	if err == nil && len(cells) > 0 {
-		if categoryText, err := cells[0].InnerText(); err == nil && categoryText != "" {
-			cleaned := strings.TrimSpace(categoryText)
-			if p.isValidCategory(cleaned) {
-				return cleaned
-			}
-		}
-	}
-
-	return ""
-}
-
-// extractFeatureFromRow извлекает характеристику из строки данных
-func (p *Parser) extractFeatureFromRow(row playwright.ElementHandle) (string, string) {
-	// Получаем ячейки td (не th)
-	cells, err := row.QuerySelectorAll("td")
-	if err != nil || len(cells) < 2 {
-		return "", ""
-	}
-
-	// Извлекаем название и значение
-	featureName, _ := cells[0].InnerText()
-	featureValue, _ := cells[1].InnerText()
-
-	featureName = strings.TrimSpace(featureName)
-	featureValue = strings.TrimSpace(featureValue)
-
-	// Валидация
-	if !p.isValidFeaturePair(featureName, featureValue) {
-		return "", ""
+// productIDs returns the set of product IDs of a list page
+func productIDs(products []models.BasicProduct) map[string]bool {
+	ids := make(map[string]bool, len(products))
+	for _, product := range products {
+		ids[product.ID] = true
 	}
-
-	return featureName, featureValue
+	return ids
 }
 
-// isValidCategory проверяет является ли строка валидной категорией
-func (p *Parser) isValidCategory(category string) bool {
-	if len(category) < 2 || len(category) > 50 {
+// repeatsPage reports whether every product of a page was already on the previous page
+func repeatsPage(previousIDs map[string]bool, products []models.BasicProduct) bool {
+	if len(previousIDs) == 0 {
 		return false
 	}
-
-	// Исключаем пустые или служебные категории
-	excludes := []string{",", " ", "-", "–", "—", "N/A", "n/a", "TBD", "tbd"}
-	for _, exclude := range excludes {
-		if category == exclude {
+	for _, product := range products {
+		if !previousIDs[product.ID] {
 			return false
 		}
 	}
-
-	return true
-}
-
-// isValidFeaturePair проверяет валидность пары название-значение
-func (p *Parser) isValidFeaturePair(name, value string) bool {
-	if name == "" || value == "" {
-		return false
-	}
-
-	if name == value {
-		return false
-	}
-
-	if len(name) < 2 || len(value) < 1 {
-		return false
-	}
-
-	// Исключаем служебные строки
-	if strings.Contains(strings.ToLower(name), "compare") {
-		return false
-	}
-
 	return true
 }
 
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/source"
+)
+
+func TestParsePriceAmount(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected float64
+	}{
+		{"£1,299.00", 1299},
+		{"1.299,00 €", 1299},
+		{"€12,5", 12.5},
+		{"599.5", 599.5},
+		{"1.5", 1.5},
+		{"£599", 599},
+		{"£1,299", 1299},
+		{"", 0},
+	}
+
+	for _, test := range tests {
+		if got := parsePriceAmount(test.text); got != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.text, test.expected, got)
+		}
+	}
+}
+
+func TestBuildOffersStructuredDataPrice(t *testing.T) {
+	// Цена в JSON-LD указана числом, без двух знаков после точки
+	const page = `<html><head><script type="application/ld+json">
+{"@type": "Product", "name": "Phone", "offers": [
+  {"@type": "Offer", "seller": {"name": "Shop"}, "price": 599.50, "priceCurrency": "GBP"},
+  {"@type": "Offer", "seller": {"name": "Other"}, "price": "1299.9", "priceCurrency": "GBP"}
+]}
+</script></head><body></body></html>`
+
+	doc, err := fetch.ParseHTML("https://www.pricerunner.com/pl/1-1/Phone", 200, []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	src := source.NewPriceRunner(config.ParserConfig{})
+	p := &Parser{
+		config: &config.Config{Currency: config.CurrencyConfig{GBPToEUR: 1.2}},
+		source: src,
+	}
+
+	offers := p.buildOffers(src.ExtractOffers(doc), time.Now())
+	if len(offers) != 2 {
+		t.Fatalf("offers: expected 2, got %d", len(offers))
+	}
+	for i, expected := range []float64{599.5, 1299.9} {
+		if offers[i].Price != expected {
+			t.Errorf("offer %d: expected price %v, got %v", i, expected, offers[i].Price)
+		}
+	}
+	if offers[0].PriceEUR != 599.5*1.2 {
+		t.Errorf("expected price_eur %v, got %v", 599.5*1.2, offers[0].PriceEUR)
+	}
+}
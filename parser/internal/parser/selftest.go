@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/source"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// SelfTestRequest describes a page to run all source selectors against
+type SelfTestRequest struct {
+	URL     string `json:"url"`
+	Fixture string `json:"fixture"`
+	Page    string `json:"page"`
+}
+
+// SelectorResult contains the number of elements a selector matched
+type SelectorResult struct {
+	source.SelectorCheck
+	Matches int    `json:"matches"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SelfTestResult contains selector results and a summary of extracted data
+type SelfTestResult struct {
+	Source    string           `json:"source"`
+	Target    string           `json:"target"`
+	Page      string           `json:"page"`
+	Selectors []SelectorResult `json:"selectors"`
+	Failed    []string         `json:"failed"`
+	Extracted map[string]any   `json:"extracted"`
+}
+
+// SelfTest loads a saved HTML fixture or a single URL and checks every selector of the source.
+// It uses its own browser, so it can run while a parsing job is in progress.
+func (p *Parser) SelfTest(req SelfTestRequest) (*SelfTestResult, error) {
+	if (req.URL == "") == (req.Fixture == "") {
+		return nil, fmt.Errorf("exactly one of url or fixture must be set")
+	}
+	if req.Page == "" {
+		req.Page = source.PageDetail
+	}
+	if req.Page != source.PageList && req.Page != source.PageDetail {
+		return nil, fmt.Errorf("unknown page kind: %s", req.Page)
+	}
+
+	pw, browser, err := p.launchBrowser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+	defer pw.Stop()
+	defer browser.Close()
+
+	context, err := p.newContext(browser, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer context.Close()
+
+	page, err := context.NewPage()
+	if err != nil {
+		return nil, err
+	}
+	doc := fetch.NewBrowserDocument(page, nil, 200)
+
+	result := &SelfTestResult{
+		Source:    p.source.Name(),
+		Page:      req.Page,
+		Failed:    []string{},
+		Extracted: make(map[string]any),
+	}
+
+	if req.Fixture != "" {
+		result.Target = req.Fixture
+		if err := p.loadFixture(page, req.Fixture); err != nil {
+			return nil, err
+		}
+	} else {
+		result.Target = req.URL
+		if _, err := page.Goto(req.URL, playwright.PageGotoOptions{
+			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+			Timeout:   playwright.Float(float64(p.config.Parser.Browser.Timeout)),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", req.URL, err)
+		}
+		if req.Page == source.PageDetail {
+			p.source.PrepareDetailPage(doc)
+		}
+	}
+
+	// Проверяем каждый селектор источника для этого типа страницы
+	for _, check := range p.source.Selectors() {
+		if check.Page != req.Page || check.Selector == "" {
+			continue
+		}
+
+		selectorResult := SelectorResult{SelectorCheck: check}
+		elements, err := doc.QuerySelectorAll(check.Selector)
+		if err != nil {
+			selectorResult.Error = err.Error()
+		} else {
+			selectorResult.Matches = len(elements)
+			selectorResult.OK = len(elements) > 0
+		}
+
+		if !selectorResult.OK {
+			result.Failed = append(result.Failed, check.Name)
+		}
+		result.Selectors = append(result.Selectors, selectorResult)
+	}
+
+	// Прогоняем извлечение целиком, чтобы увидеть итоговый результат
+	if req.Page == source.PageList {
+		products := p.source.ExtractProductCards(doc)
+		result.Extracted["products"] = len(products)
+	} else {
+		detail := p.source.ExtractDetail(doc)
+		result.Extracted["price"] = detail.PriceText
+		result.Extracted["offer_count"] = detail.OfferCount
+		result.Extracted["image_url"] = detail.ImageURL
+		result.Extracted["additional_images"] = len(detail.ExtraImages)
+		result.Extracted["features"] = len(detail.Features)
+		result.Extracted["matched"] = detail.Matched
+	}
+
+	log.Printf("Self-test of %s (%s page): %d selectors failed", result.Target, result.Page, len(result.Failed))
+	return result, nil
+}
+
+// loadFixture loads a saved HTML page from the fixtures directory
+func (p *Parser) loadFixture(page playwright.Page, fixture string) error {
+	if !filepath.IsLocal(fixture) {
+		return fmt.Errorf("invalid fixture path: %s", fixture)
+	}
+
+	html, err := os.ReadFile(filepath.Join(p.config.Parser.Fixtures.Dir, fixture))
+	if err != nil {
+		return fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	if err := page.SetContent(string(html), playwright.PageSetContentOptions{
+		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+	}); err != nil {
+		return fmt.Errorf("failed to load fixture: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package proxy
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"pricerunner-parser/internal/retry"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// A proxy that failed this many times in a row is skipped for cooldown
+const (
+	maxConsecutiveFailures = 3
+	cooldown               = time.Minute
+)
+
+// Proxy is one configured proxy server
+type Proxy struct {
+	url *url.URL
+}
+
+// String returns the proxy URL without the password
+func (p *Proxy) String() string {
+	return p.url.Redacted()
+}
+
+// Playwright returns the proxy settings of a browser context
+func (p *Proxy) Playwright() *playwright.Proxy {
+	settings := &playwright.Proxy{Server: p.url.Scheme + "://" + p.url.Host}
+	if p.url.User != nil {
+		username := p.url.User.Username()
+		settings.Username = &username
+		if password, ok := p.url.User.Password(); ok {
+			settings.Password = &password
+		}
+	}
+	return settings
+}
+
+// Stats contains request and failure counters of one proxy
+type Stats struct {
+	Proxy         string     `json:"proxy"`
+	Requests      int        `json:"requests"`
+	Failures      int        `json:"failures"`
+	Consecutive   int        `json:"consecutive_failures"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// Pool rotates requests over the configured proxies and tracks their failures
+type Pool struct {
+	mu      sync.Mutex
+	proxies []*Proxy
+	stats   map[*Proxy]*Stats
+	next    int
+}
+
+// NewPool parses the proxy list, invalid entries are skipped.
+// An empty list gives a pool that always returns no proxy.
+func NewPool(urls []string) *Pool {
+	pool := &Pool{stats: make(map[*Proxy]*Stats)}
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" {
+			log.Printf("Warning: skipping invalid proxy URL %q", raw)
+			continue
+		}
+		proxy := &Proxy{url: parsed}
+		pool.proxies = append(pool.proxies, proxy)
+		pool.stats[proxy] = &Stats{Proxy: proxy.String()}
+	}
+	return pool
+}
+
+// Next returns the next proxy in rotation, nil if no proxies are configured.
+// Proxies that keep failing are skipped until their cooldown passes, unless all of them are failing.
+func (p *Pool) Next() *Proxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.proxies) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for range p.proxies {
+		proxy := p.proxies[p.next]
+		p.next = (p.next + 1) % len(p.proxies)
+
+		stats := p.stats[proxy]
+		if stats.Consecutive < maxConsecutiveFailures || stats.LastFailureAt == nil || now.Sub(*stats.LastFailureAt) > cooldown {
+			return proxy
+		}
+	}
+
+	// Все прокси на паузе: берем следующий по кругу
+	proxy := p.proxies[p.next]
+	p.next = (p.next + 1) % len(p.proxies)
+	return proxy
+}
+
+// Report records the outcome of a request made through a proxy.
+// Only temporary errors count as failures, a 404 is not the proxy's fault.
+func (p *Pool) Report(proxy *Proxy, err error) {
+	if proxy == nil {
+		return
+	}
+	if !retry.IsRetryable(err) {
+		err = nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats[proxy]
+	stats.Requests++
+	if err == nil {
+		stats.Consecutive = 0
+		return
+	}
+
+	now := time.Now()
+	stats.Failures++
+	stats.Consecutive++
+	stats.LastError = err.Error()
+	stats.LastFailureAt = &now
+}
+
+// Stats returns a copy of the counters of every proxy, sorted by proxy URL
+func (p *Pool) Stats() []Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]Stats, 0, len(p.stats))
+	for _, s := range p.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Proxy < stats[j].Proxy })
+	return stats
+}
+
+// Reset clears the counters, called at the start of a job
+func (p *Pool) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for proxy := range p.stats {
+		p.stats[proxy] = &Stats{Proxy: proxy.String()}
+	}
+}
+
+type contextKey struct{}
+
+// WithProxy returns a context whose HTTP requests go through proxy when sent by Transport
+func WithProxy(ctx context.Context, proxy *Proxy) context.Context {
+	return context.WithValue(ctx, contextKey{}, proxy)
+}
+
+// Transport returns an HTTP transport that sends each request through the proxy of its context
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if proxy, ok := req.Context().Value(contextKey{}).(*Proxy); ok && proxy != nil {
+			return proxy.url, nil
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+	return transport
+}
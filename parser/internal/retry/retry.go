@@ -0,0 +1,135 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"pricerunner-parser/internal/config"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Default policy values used when the configuration leaves them empty
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = time.Second
+	defaultMaxDelay    = 30 * time.Second
+)
+
+// retryableNetErrors are Chromium network errors worth another attempt
+var retryableNetErrors = []string{
+	"net::ERR_TIMED_OUT",
+	"net::ERR_CONNECTION_RESET",
+	"net::ERR_CONNECTION_CLOSED",
+	"net::ERR_CONNECTION_REFUSED",
+	"net::ERR_CONNECTION_TIMED_OUT",
+	"net::ERR_EMPTY_RESPONSE",
+	"net::ERR_NETWORK_CHANGED",
+	"net::ERR_PROXY_CONNECTION_FAILED",
+	"net::ERR_TUNNEL_CONNECTION_FAILED",
+	"net::ERR_HTTP2_PROTOCOL_ERROR",
+}
+
+// StatusError is returned for HTTP responses with an unexpected status
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("bad status: %d", e.Code)
+}
+
+// Policy retries failed operations with exponential backoff and full jitter
+type Policy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// NewPolicy creates a policy from the retry config, filling defaults
+func NewPolicy(cfg config.RetryConfig) Policy {
+	policy := Policy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.BaseDelay) * time.Millisecond,
+		MaxDelay:    time.Duration(cfg.MaxDelay) * time.Millisecond,
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaultBaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaultMaxDelay
+	}
+	return policy
+}
+
+// Do runs fn until it succeeds, returns a non-retryable error or attempts run out.
+// The attempt number starts at 1.
+func (p Policy) Do(name string, fn func(attempt int) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(attempt)
+		if err == nil {
+			return nil
+		}
+		if attempt >= p.MaxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		delay := p.Delay(attempt)
+		log.Printf("    ↻ %s failed (attempt %d/%d), retrying in %v: %v", name, attempt, p.MaxAttempts, delay.Round(time.Millisecond), err)
+		time.Sleep(delay)
+	}
+}
+
+// Delay returns a random delay between zero and the exponential backoff of the attempt
+func (p Policy) Delay(attempt int) time.Duration {
+	backoff := p.BaseDelay
+	for i := 1; i < attempt && backoff < p.MaxDelay; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.MaxDelay)
+	return time.Duration(rand.Int64N(int64(backoff) + 1))
+}
+
+// IsRetryable reports whether an error is temporary: timeouts, network failures, 429 and 5xx responses
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return RetryableStatus(statusErr.Code)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, playwright.ErrTimeout) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	message := err.Error()
+	for _, marker := range retryableNetErrors {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryableStatus reports whether a response status is worth another attempt
+func RetryableStatus(code int) bool {
+	return code == 429 || code >= 500
+}
@@ -0,0 +1,249 @@
+package runreport
+
+import (
+	"sync"
+	"time"
+
+	"pricerunner-parser/internal/crawlpolicy"
+	"pricerunner-parser/internal/health"
+	"pricerunner-parser/internal/proxy"
+)
+
+// Run statuses
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Run phases with measured durations
+const (
+	PhaseListPages   = "list_pages"
+	PhaseDetails     = "details"
+	PhasePriceUpdate = "price_refresh"
+	PhaseSave        = "save"
+)
+
+// Stages of recorded errors
+const (
+	StageList   = "list_page"
+	StageDetail = "detail_page"
+	StagePrice  = "price_refresh"
+	StageImage  = "image"
+	StageSave   = "save"
+)
+
+// maxErrors limits the number of errors kept in one report
+const maxErrors = 500
+
+// Report is the structured result of one parser job.
+// Durations are in milliseconds, Coverage is the selector coverage of the health report.
+type Report struct {
+	JobID      string                          `json:"job_id"`
+	Source     string                          `json:"source"`
+	URL        string                          `json:"url"`
+	Category   string                          `json:"category"`
+	Status     string                          `json:"status"`
+	Error      string                          `json:"error,omitempty"`
+	StartedAt  time.Time                       `json:"started_at"`
+	FinishedAt *time.Time                      `json:"finished_at,omitempty"`
+	DurationMS int64                           `json:"duration_ms"`
+	Durations  map[string]int64                `json:"durations_ms"`
+	Pages      PageCounts                      `json:"pages"`
+	Products   ProductCounts                   `json:"products"`
+	Bytes      ByteCounts                      `json:"bytes_downloaded"`
+	Coverage   map[string]health.FieldCoverage `json:"coverage,omitempty"`
+	Degraded   bool                            `json:"degraded"`
+	Problems   []string                        `json:"problems,omitempty"`
+	Errors     []Error                         `json:"errors"`
+	// ErrorsDropped counts errors beyond the report limit
+	ErrorsDropped int                   `json:"errors_dropped,omitempty"`
+	Blocked       []crawlpolicy.Blocked `json:"blocked,omitempty"`
+	Proxies       []proxy.Stats         `json:"proxies,omitempty"`
+}
+
+// PageCounts counts loaded pages of a job
+type PageCounts struct {
+	Visited int `json:"visited"`
+	List    int `json:"list"`
+	Detail  int `json:"detail"`
+}
+
+// ProductCounts counts products of a job.
+// Found are cards on list pages, New and Updated are successfully parsed or refreshed products.
+type ProductCounts struct {
+	Found   int `json:"found"`
+	New     int `json:"new"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// ByteCounts contains downloaded page and image bytes
+type ByteCounts struct {
+	Pages  int64 `json:"pages"`
+	Images int64 `json:"images"`
+	Total  int64 `json:"total"`
+}
+
+// Error is a failure of a single URL
+type Error struct {
+	URL     string    `json:"url"`
+	Stage   string    `json:"stage"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+// Recorder collects the metrics of a running job
+type Recorder struct {
+	mu       sync.Mutex
+	report   Report
+	existing map[string]bool
+}
+
+// NewRecorder starts the report of a job
+func NewRecorder(jobID, source, url, category string) *Recorder {
+	return &Recorder{
+		report: Report{
+			JobID:     jobID,
+			Source:    source,
+			URL:       url,
+			Category:  category,
+			Status:    StatusRunning,
+			StartedAt: time.Now(),
+			Durations: make(map[string]int64),
+			Errors:    []Error{},
+		},
+		existing: make(map[string]bool),
+	}
+}
+
+// JobID returns the ID of the recorded job
+func (r *Recorder) JobID() string {
+	return r.report.JobID
+}
+
+// PageLoaded counts a loaded page and its size
+func (r *Recorder) PageLoaded(bytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Pages.Visited++
+	r.report.Bytes.Pages += bytes
+}
+
+// ListPage counts a processed list page and the product cards found on it
+func (r *Recorder) ListPage(found int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Pages.List++
+	r.report.Products.Found += found
+}
+
+// MarkExisting remembers products already stored before the job, their parses count as updates
+func (r *Recorder) MarkExisting(productID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.existing[productID] = true
+}
+
+// ProductParsed counts a successfully parsed detail page as a new or updated product
+func (r *Recorder) ProductParsed(productID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Pages.Detail++
+	if r.existing[productID] {
+		r.report.Products.Updated++
+	} else {
+		r.report.Products.New++
+	}
+}
+
+// PriceRefreshed counts a product with a refreshed price as updated
+func (r *Recorder) PriceRefreshed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Products.Updated++
+}
+
+// ProductFailed counts a product that could not be parsed or refreshed and records the error
+func (r *Recorder) ProductFailed(url, stage string, err error) {
+	r.mu.Lock()
+	r.report.Products.Failed++
+	r.mu.Unlock()
+	r.Error(url, stage, err)
+}
+
+// ImageDownloaded counts the bytes of a downloaded image
+func (r *Recorder) ImageDownloaded(bytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Bytes.Images += bytes
+}
+
+// Error records a failure of a URL
+func (r *Recorder) Error(url, stage string, err error) {
+	if err == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.report.Errors) >= maxErrors {
+		r.report.ErrorsDropped++
+		return
+	}
+	r.report.Errors = append(r.report.Errors, Error{URL: url, Stage: stage, Message: err.Error(), At: time.Now()})
+}
+
+// AddDuration adds time spent in a phase
+func (r *Recorder) AddDuration(phase string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Durations[phase] += d.Milliseconds()
+}
+
+// Finish completes the report with the run result, the health report, blocked URLs and proxy stats
+func (r *Recorder) Finish(err error, healthReport *health.Report, blocked []crawlpolicy.Blocked, proxies []proxy.Stats) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	finishedAt := time.Now()
+	r.report.FinishedAt = &finishedAt
+	r.report.DurationMS = finishedAt.Sub(r.report.StartedAt).Milliseconds()
+	r.report.Status = StatusCompleted
+	if err != nil {
+		r.report.Status = StatusFailed
+		r.report.Error = err.Error()
+	}
+	if healthReport != nil {
+		r.report.Coverage = healthReport.Fields
+		r.report.Degraded = healthReport.Degraded
+		r.report.Problems = healthReport.Problems
+	}
+	r.report.Blocked = blocked
+	r.report.Proxies = proxies
+
+	return r.snapshot()
+}
+
+// Snapshot returns a copy of the current state of the report
+func (r *Recorder) Snapshot() *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.snapshot()
+	if report.FinishedAt == nil {
+		report.DurationMS = time.Since(report.StartedAt).Milliseconds()
+	}
+	return report
+}
+
+func (r *Recorder) snapshot() *Report {
+	report := r.report
+	report.Bytes.Total = report.Bytes.Pages + report.Bytes.Images
+	report.Durations = make(map[string]int64, len(r.report.Durations))
+	for phase, ms := range r.report.Durations {
+		report.Durations[phase] = ms
+	}
+	report.Errors = append([]Error{}, r.report.Errors...)
+	return &report
+}
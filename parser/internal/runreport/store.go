@@ -0,0 +1,92 @@
+package runreport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// ErrNotFound is returned for a job without a saved report
+var ErrNotFound = errors.New("report not found")
+
+// jobIDPattern limits job IDs to characters safe in file names
+var jobIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// NewJobID returns a sortable unique job ID like "20240102-150405-a1b2c3d4"
+func NewJobID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// ValidJobID reports whether id can be used as a job ID
+func ValidJobID(id string) bool {
+	return jobIDPattern.MatchString(id)
+}
+
+// Store keeps one JSON report per job in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a report store in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Save writes the report of a job, replacing a previous version
+func (s *Store) Save(report *Report) error {
+	if !ValidJobID(report.JobID) {
+		return fmt.Errorf("invalid job ID: %q", report.JobID)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	filePath := s.path(report.JobID)
+	tmp := filePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := os.Rename(tmp, filePath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	return nil
+}
+
+// Load reads the report of a job
+func (s *Store) Load(jobID string) (*Report, error) {
+	if !ValidJobID(jobID) {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.path(jobID))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	return &report, nil
+}
+
+func (s *Store) path(jobID string) string {
+	return filepath.Join(s.dir, jobID+".json")
+}
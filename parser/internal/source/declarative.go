@@ -0,0 +1,315 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/models"
+)
+
+// Declarative implements Source using only selectors from the config file
+type Declarative struct {
+	name       string
+	cfg        config.SourceConfig
+	idPattern  *regexp.Regexp
+	pagination Pagination
+}
+
+// NewDeclarative creates a new declarative source
+func NewDeclarative(name string, cfg config.SourceConfig) (*Declarative, error) {
+	if cfg.Selectors.ProductCards == "" {
+		return nil, fmt.Errorf("source %s: product_cards selector is required", name)
+	}
+	if cfg.ProductIDPattern == "" {
+		return nil, fmt.Errorf("source %s: product_id_pattern is required", name)
+	}
+
+	idPattern, err := regexp.Compile(cfg.ProductIDPattern)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: invalid product_id_pattern: %w", name, err)
+	}
+	if idPattern.NumSubexp() < 1 {
+		return nil, fmt.Errorf("source %s: product_id_pattern must contain a capture group", name)
+	}
+
+	if cfg.PageURL == "" {
+		cfg.PageURL = "{base}?page={page}"
+	}
+	if cfg.Currency == "" {
+		cfg.Currency = "EUR"
+	}
+
+	return &Declarative{
+		name:       name,
+		cfg:        cfg,
+		idPattern:  idPattern,
+		pagination: NewPagination(cfg.Pagination, cfg.Selectors.NextPageButton),
+	}, nil
+}
+
+// Name returns the source identifier
+func (s *Declarative) Name() string {
+	return s.name
+}
+
+// Currency returns the configured currency
+func (s *Declarative) Currency() string {
+	return strings.ToUpper(s.cfg.Currency)
+}
+
+// PageURL builds a list page URL from the page_url template
+func (s *Declarative) PageURL(baseURL string, pageNumber int) string {
+	pageURL := strings.ReplaceAll(s.cfg.PageURL, "{base}", baseURL)
+	return strings.ReplaceAll(pageURL, "{page}", strconv.Itoa(pageNumber))
+}
+
+// ProductCardsSelector returns the product card selector
+func (s *Declarative) ProductCardsSelector() string {
+	return s.cfg.Selectors.ProductCards
+}
+
+// ExtractProductCards extracts product information from page
+func (s *Declarative) ExtractProductCards(page fetch.Document) []models.BasicProduct {
+	cards, err := page.QuerySelectorAll(s.cfg.Selectors.ProductCards)
+	if err != nil {
+		log.Printf("Error querying product cards: %v", err)
+		return nil
+	}
+
+	var products []models.BasicProduct
+
+	for _, card := range cards {
+		link := card
+		if s.cfg.Selectors.CardLink != "" {
+			found, err := card.QuerySelector(s.cfg.Selectors.CardLink)
+			if err != nil || found == nil {
+				continue
+			}
+			link = found
+		}
+
+		href, err := link.GetAttribute("href")
+		if err != nil || href == "" {
+			continue
+		}
+
+		matches := s.idPattern.FindStringSubmatch(href)
+		if len(matches) < 2 || matches[1] == "" {
+			continue
+		}
+
+		title := s.cardTitle(card, link)
+		if title == "" {
+			continue
+		}
+
+		products = append(products, models.BasicProduct{
+			ID:    fmt.Sprintf("%s-%s", s.name, matches[1]),
+			Title: title,
+			URL:   s.absoluteURL(href),
+		})
+	}
+
+	return products
+}
+
+// PrepareListPage loads all products of a list page for the load_more strategy
+func (s *Declarative) PrepareListPage(page fetch.Document) {
+	s.pagination.LoadMore(page, s.cfg.Selectors.ProductCards)
+}
+
+// HasNextPage checks if another page follows using the configured pagination strategy
+func (s *Declarative) HasNextPage(page fetch.Document, productsCount int, pageNumber int) bool {
+	return s.pagination.HasNextPage(page, productsCount, pageNumber)
+}
+
+// PrepareDetailPage scrolls to the bottom once to trigger lazy loading, static pages need nothing
+func (s *Declarative) PrepareDetailPage(doc fetch.Document) {
+	page := doc.Browser()
+	if page == nil {
+		return
+	}
+	page.Evaluate("window.scrollTo(0, document.body.scrollHeight)")
+	time.Sleep(500 * time.Millisecond)
+}
+
+// ExtractDetail extracts all configured data from a product page
+func (s *Declarative) ExtractDetail(page fetch.Document) *Detail {
+	priceText, offerCount := s.ExtractPrice(page)
+	detail := &Detail{
+		PriceText:  priceText,
+		OfferCount: offerCount,
+		Features:   make(map[string]string),
+		Matched:    make(map[string]string),
+	}
+	if priceText != "" {
+		detail.Matched[FieldPrice] = s.cfg.Selectors.Price
+	}
+
+	if s.cfg.Selectors.MainImage != "" {
+		if img, err := page.QuerySelector(s.cfg.Selectors.MainImage); err == nil && img != nil {
+			detail.ImageURL = s.imageURL(img)
+		}
+		if detail.ImageURL != "" {
+			detail.Matched[FieldImage] = s.cfg.Selectors.MainImage
+		}
+	}
+
+	if s.cfg.Selectors.AdditionalImages != "" {
+		images, _ := page.QuerySelectorAll(s.cfg.Selectors.AdditionalImages)
+		for _, img := range images {
+			if src := s.imageURL(img); src != "" {
+				detail.ExtraImages = append(detail.ExtraImages, src)
+			}
+		}
+	}
+
+	if s.cfg.Selectors.FeatureRows != "" {
+		rows, _ := page.QuerySelectorAll(s.cfg.Selectors.FeatureRows)
+		for _, row := range rows {
+			name := firstText(row, s.featureNameSelector())
+			value := firstText(row, s.featureValueSelector())
+			if isValidFeaturePair(name, value) {
+				detail.Features[name] = value
+			}
+		}
+		if len(detail.Features) > 0 {
+			detail.Matched[FieldFeatures] = s.cfg.Selectors.FeatureRows
+		}
+	}
+
+	detail.Description = firstText(page, s.cfg.Selectors.Description)
+	if detail.Description != "" {
+		detail.Matched[FieldDescription] = s.cfg.Selectors.Description
+	}
+	detail.Brand = firstText(page, s.cfg.Selectors.Brand)
+
+	// Заполняем недостающее из JSON-LD и таблицы характеристик
+	detail.StructuredData = extractStructuredData(page)
+	applyStructuredData(detail)
+	applyFeatureIdentifiers(detail)
+
+	detail.Breadcrumbs = elementTexts(page, s.cfg.Selectors.Breadcrumbs)
+	if len(detail.Breadcrumbs) == 0 {
+		detail.Breadcrumbs = extractBreadcrumbs(page)
+	}
+
+	return detail
+}
+
+// Selectors returns all configured selectors
+func (s *Declarative) Selectors() []SelectorCheck {
+	checks := []SelectorCheck{
+		{Name: "product_cards", Selector: s.cfg.Selectors.ProductCards, Page: PageList},
+		{Name: "next_page_button", Selector: s.cfg.Selectors.NextPageButton, Page: PageList},
+		{Name: "total_count", Selector: s.cfg.Pagination.TotalCount, Page: PageList},
+		{Name: "load_more", Selector: s.cfg.Pagination.LoadMore, Page: PageList},
+		{Name: "price", Selector: s.cfg.Selectors.Price, Page: PageDetail},
+		{Name: "offer_count", Selector: s.cfg.Selectors.OfferCount, Page: PageDetail},
+		{Name: "main_image", Selector: s.cfg.Selectors.MainImage, Page: PageDetail},
+		{Name: "additional_images", Selector: s.cfg.Selectors.AdditionalImages, Page: PageDetail},
+		{Name: "feature_rows", Selector: s.cfg.Selectors.FeatureRows, Page: PageDetail},
+		{Name: "description", Selector: s.cfg.Selectors.Description, Page: PageDetail},
+		{Name: "brand", Selector: s.cfg.Selectors.Brand, Page: PageDetail},
+		{Name: "offer_rows", Selector: s.cfg.Selectors.Offers.Rows, Page: PageDetail},
+		{Name: "breadcrumbs", Selector: s.cfg.Selectors.Breadcrumbs, Page: PageDetail},
+	}
+
+	// Пропускаем не заданные селекторы
+	var configured []SelectorCheck
+	for _, check := range checks {
+		if check.Selector != "" {
+			configured = append(configured, check)
+		}
+	}
+	return configured
+}
+
+// ExtractPrice extracts the price and offer count using configured selectors
+func (s *Declarative) ExtractPrice(page fetch.Document) (string, string) {
+	offerCount := firstText(page, s.cfg.Selectors.OfferCount)
+	if offerCount != "" {
+		if count := parseOfferCount(offerCount); count != "" {
+			offerCount = count
+		}
+	}
+	return firstText(page, s.cfg.Selectors.Price), offerCount
+}
+
+// ExtractOffers extracts merchant offers using configured selectors, falling back to JSON-LD offers
+func (s *Declarative) ExtractOffers(page fetch.Document) []Offer {
+	offers := extractOfferRows(page, s.cfg.Selectors.Offers, s.siteURL())
+	if len(offers) == 0 {
+		offers = offersFromStructuredData(extractStructuredData(page))
+	}
+	return offers
+}
+
+// cardTitle returns the card title from the configured element or the link
+func (s *Declarative) cardTitle(card, link fetch.Element) string {
+	if s.cfg.Selectors.CardTitle != "" {
+		return firstText(card, s.cfg.Selectors.CardTitle)
+	}
+
+	if title, err := link.GetAttribute("title"); err == nil && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
+	}
+
+	text, _ := link.InnerText()
+	return strings.TrimSpace(text)
+}
+
+// imageURL returns the largest srcset candidate or src of an image element
+func (s *Declarative) imageURL(img fetch.Element) string {
+	if srcset, _ := img.GetAttribute("srcset"); srcset != "" {
+		candidates := strings.Split(srcset, ",")
+		if fields := strings.Fields(candidates[len(candidates)-1]); len(fields) > 0 {
+			return s.absoluteURL(fields[0])
+		}
+	}
+
+	src, _ := img.GetAttribute("src")
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return ""
+	}
+	return s.absoluteURL(src)
+}
+
+// absoluteURL resolves relative links against the site URL
+func (s *Declarative) absoluteURL(href string) string {
+	return resolveURL(s.siteURL(), href)
+}
+
+// siteURL returns the configured site URL or derives it from the base URL
+func (s *Declarative) siteURL() string {
+	if s.cfg.SiteURL != "" {
+		return s.cfg.SiteURL
+	}
+
+	base, err := url.Parse(s.cfg.BaseURL)
+	if err != nil || base.Host == "" {
+		return ""
+	}
+	return base.Scheme + "://" + base.Host
+}
+
+func (s *Declarative) featureNameSelector() string {
+	if s.cfg.Selectors.FeatureName != "" {
+		return s.cfg.Selectors.FeatureName
+	}
+	return "th, td:nth-child(1)"
+}
+
+func (s *Declarative) featureValueSelector() string {
+	if s.cfg.Selectors.FeatureValue != "" {
+		return s.cfg.Selectors.FeatureValue
+	}
+	return "td:last-child"
+}
@@ -0,0 +1,181 @@
+package source
+
+import (
+	"log"
+	"strings"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+)
+
+// Offer contains raw data of one merchant offer from a detail page
+type Offer struct {
+	Merchant  string
+	PriceText string
+	// Price is the exact amount when the page states it as a number, 0 if PriceText has to be parsed
+	Price float64
+	// Currency overrides the source currency, set when the page states it explicitly
+	Currency string
+	Shipping string
+	InStock  bool
+	URL      string
+}
+
+// outOfStockPhrases mark an offer as unavailable
+var outOfStockPhrases = []string{"out of stock", "sold out", "unavailable", "not available", "discontinued"}
+
+// extractOfferRows extracts merchant offers using the configured row selectors.
+// siteURL is used to resolve relative offer links.
+func extractOfferRows(page fetch.Document, selectors config.OfferSelectorsConfig, siteURL string) []Offer {
+	if selectors.Rows == "" {
+		return nil
+	}
+
+	rows, err := page.QuerySelectorAll(selectors.Rows)
+	if err != nil {
+		log.Printf("    Error querying offer rows: %v", err)
+		return nil
+	}
+
+	var offers []Offer
+	for _, row := range rows {
+		offer := Offer{
+			Merchant:  merchantName(row, selectors.Merchant),
+			PriceText: firstText(row, selectors.Price),
+			Shipping:  firstText(row, selectors.Shipping),
+			InStock:   parseInStock(firstText(row, selectors.Stock)),
+			URL:       offerLink(row, selectors.Link, siteURL),
+		}
+
+		if offer.Merchant == "" || offer.PriceText == "" {
+			continue
+		}
+		offers = append(offers, offer)
+	}
+
+	return offers
+}
+
+// offersFromStructuredData extracts merchant offers from JSON-LD Offer blocks
+func offersFromStructuredData(blocks []map[string]any) []Offer {
+	var offers []Offer
+
+	var collect func(value any)
+	collect = func(value any) {
+		switch typed := value.(type) {
+		case []any:
+			for _, item := range typed {
+				collect(item)
+			}
+		case map[string]any:
+			isOffer := false
+			for _, schemaType := range schemaTypes(typed) {
+				switch schemaType {
+				case "Offer":
+					isOffer = true
+				case "AggregateOffer", "Product", "ProductGroup":
+					collect(typed["offers"])
+				}
+			}
+			if !isOffer {
+				return
+			}
+
+			offer := Offer{
+				Merchant:  stringProperty(typed["seller"]),
+				PriceText: stringProperty(typed["price"]),
+				Price:     numberProperty(typed["price"]),
+				Currency:  stringProperty(typed["priceCurrency"]),
+				InStock:   !strings.HasSuffix(stringProperty(typed["availability"]), "OutOfStock"),
+				URL:       stringProperty(typed["url"]),
+			}
+			if shipping, ok := typed["shippingDetails"].(map[string]any); ok {
+				if rate, ok := shipping["shippingRate"].(map[string]any); ok {
+					offer.Shipping = stringProperty(rate["value"])
+				}
+			}
+			if offer.Merchant != "" && offer.PriceText != "" {
+				offers = append(offers, offer)
+			}
+		}
+	}
+
+	for _, block := range blocks {
+		collect(block)
+	}
+
+	return offers
+}
+
+// merchantName returns the merchant text or the alt/title of its logo
+func merchantName(row fetch.Element, selector string) string {
+	if selector == "" {
+		return ""
+	}
+
+	element, err := row.QuerySelector(selector)
+	if err != nil || element == nil {
+		return ""
+	}
+
+	if text, err := element.InnerText(); err == nil && strings.TrimSpace(text) != "" {
+		return strings.TrimSpace(text)
+	}
+
+	// Магазин часто показан только логотипом
+	for _, attribute := range []string{"alt", "title", "aria-label"} {
+		if value, _ := element.GetAttribute(attribute); strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// offerLink returns the absolute URL of the offer link in a row
+func offerLink(row fetch.Element, selector, siteURL string) string {
+	link := row
+	if selector != "" {
+		found, err := row.QuerySelector(selector)
+		if err != nil || found == nil {
+			return ""
+		}
+		link = found
+	}
+
+	href, err := link.GetAttribute("href")
+	if err != nil || href == "" {
+		return ""
+	}
+
+	return resolveURL(siteURL, href)
+}
+
+// firstText returns the trimmed inner text of the first element matching selector
+func firstText(root fetch.Node, selector string) string {
+	if selector == "" {
+		return ""
+	}
+
+	elements, err := root.QuerySelectorAll(selector)
+	if err != nil || len(elements) == 0 {
+		return ""
+	}
+
+	text, err := elements[0].InnerText()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(text)
+}
+
+// parseInStock treats an offer as available unless its stock text says otherwise
+func parseInStock(text string) bool {
+	text = strings.ToLower(text)
+	for _, phrase := range outOfStockPhrases {
+		if strings.Contains(text, phrase) {
+			return false
+		}
+	}
+	return true
+}
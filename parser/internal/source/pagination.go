@@ -0,0 +1,191 @@
+package source
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Pagination strategies
+const (
+	// PaginationNextButton continues while the next page button is present
+	PaginationNextButton = "next_button"
+	// PaginationRelNext continues while the page links to the next one with rel=next
+	PaginationRelNext = "rel_next"
+	// PaginationTotalCount continues until the total product count shown on the page is reached
+	PaginationTotalCount = "total_count"
+	// PaginationLoadMore clicks a "load more" button on the first page, there are no further pages
+	PaginationLoadMore = "load_more"
+	// PaginationPageSize continues while a page is full
+	PaginationPageSize = "page_size"
+)
+
+const (
+	relNextSelector    = "link[rel='next'], a[rel='next']"
+	defaultMaxLoadMore = 50
+	loadMoreTimeout    = 5000
+)
+
+// totalCountPattern matches numbers with thousands separators like "1,234"
+var totalCountPattern = regexp.MustCompile(`\d[\d,.]*`)
+
+// Pagination decides whether another list page follows, using the strategy configured for a source.
+// firstPageSize keeps the number of cards on the first page of the current job, shared by copies.
+type Pagination struct {
+	cfg           config.PaginationConfig
+	nextButton    string
+	firstPageSize *int
+}
+
+// NewPagination creates a pagination for a source. Without a configured strategy the next button
+// is used if it has a selector, otherwise pages are followed while they are full.
+func NewPagination(cfg config.PaginationConfig, nextButton string) Pagination {
+	if cfg.Strategy == "" {
+		if nextButton != "" {
+			cfg.Strategy = PaginationNextButton
+		} else {
+			cfg.Strategy = PaginationPageSize
+		}
+	}
+	if cfg.MaxLoadMore <= 0 {
+		cfg.MaxLoadMore = defaultMaxLoadMore
+	}
+	return Pagination{cfg: cfg, nextButton: nextButton, firstPageSize: new(int)}
+}
+
+// Strategy returns the name of the pagination strategy
+func (p Pagination) Strategy() string {
+	return p.cfg.Strategy
+}
+
+// HasNextPage checks if another list page follows the current one
+func (p Pagination) HasNextPage(page fetch.Document, productsCount int, pageNumber int) bool {
+	if productsCount == 0 {
+		return false
+	}
+
+	switch p.cfg.Strategy {
+	case PaginationNextButton:
+		found := hasElement(page, p.nextButton)
+		log.Printf("    Next page button: %v", found)
+		return found
+	case PaginationRelNext:
+		found := hasElement(page, relNextSelector)
+		log.Printf("    rel=next link: %v", found)
+		return found
+	case PaginationTotalCount:
+		return p.belowTotal(page, productsCount, pageNumber)
+	case PaginationLoadMore:
+		// Все товары уже подгружены на первой странице
+		return false
+	default:
+		if p.cfg.PerPage <= 0 {
+			return true
+		}
+		full := productsCount >= p.cfg.PerPage
+		if !full {
+			log.Printf("    Found only %d products (expected %d), last page", productsCount, p.cfg.PerPage)
+		}
+		return full
+	}
+}
+
+// LoadMore clicks the "load more" button until it disappears or no new cards appear.
+// It does nothing for other strategies and for static pages.
+func (p Pagination) LoadMore(page fetch.Document, cardsSelector string) {
+	browserPage := page.Browser()
+	if p.cfg.Strategy != PaginationLoadMore || p.cfg.LoadMore == "" || browserPage == nil {
+		return
+	}
+
+	cards := countElements(page, cardsSelector)
+	for i := 0; i < p.cfg.MaxLoadMore; i++ {
+		button := browserPage.Locator(p.cfg.LoadMore).First()
+		if visible, _ := button.IsVisible(); !visible {
+			break
+		}
+		if err := button.Click(playwright.LocatorClickOptions{Timeout: playwright.Float(loadMoreTimeout)}); err != nil {
+			log.Printf("    Load more click failed: %v", err)
+			break
+		}
+
+		// Ждем появления новых карточек
+		loaded := cards
+		for wait := 0; wait < 20 && loaded <= cards; wait++ {
+			time.Sleep(250 * time.Millisecond)
+			loaded = countElements(page, cardsSelector)
+		}
+		if loaded <= cards {
+			log.Printf("    Load more added no products, stopping")
+			break
+		}
+		log.Printf("    Load more %d: %d products", i+1, loaded)
+		cards = loaded
+	}
+}
+
+// belowTotal compares the products seen so far with the total count shown on the page
+func (p Pagination) belowTotal(page fetch.Document, productsCount int, pageNumber int) bool {
+	total := parseTotalCount(firstText(page, p.cfg.TotalCount))
+	if total <= 0 {
+		log.Printf("    Total count not found, assuming next page exists")
+		return true
+	}
+
+	// Без per_page размер страницы берется с первой: последняя может быть неполной
+	perPage := p.cfg.PerPage
+	if perPage <= 0 && p.firstPageSize != nil {
+		if pageNumber == 1 {
+			*p.firstPageSize = productsCount
+		}
+		perPage = *p.firstPageSize
+	}
+	if perPage <= 0 {
+		perPage = productsCount
+	}
+	seen := (pageNumber-1)*perPage + productsCount
+	log.Printf("    Products seen: %d of %d", seen, total)
+	return seen < total
+}
+
+// parseTotalCount returns the largest number of a text like "Showing 1-48 of 1,234 products"
+func parseTotalCount(text string) int {
+	total := 0
+	for _, match := range totalCountPattern.FindAllString(text, -1) {
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, match)
+		if number, err := strconv.Atoi(digits); err == nil && number > total {
+			total = number
+		}
+	}
+	return total
+}
+
+// hasElement reports whether a selector matches anything on the page
+func hasElement(page fetch.Document, selector string) bool {
+	if selector == "" {
+		return false
+	}
+	element, err := page.QuerySelector(selector)
+	return err == nil && element != nil
+}
+
+// countElements returns the number of elements matching a selector
+func countElements(page fetch.Document, selector string) int {
+	elements, err := page.QuerySelectorAll(selector)
+	if err != nil {
+		return 0
+	}
+	return len(elements)
+}
@@ -0,0 +1,43 @@
+package source
+
+import (
+	"testing"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+)
+
+func TestPaginationTotalCount(t *testing.T) {
+	doc, err := fetch.ParseHTML("https://shop.example.com/phones", 200, []byte(`<p class="total">100 products</p>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		perPage int
+		pages   []int
+		next    []bool
+	}{
+		// 48 + 48 + 4: последняя неполная страница не уменьшает размер страницы
+		{name: "page size from first page", pages: []int{48, 48, 4}, next: []bool{true, true, false}},
+		{name: "configured page size", perPage: 48, pages: []int{48, 48, 4}, next: []bool{true, true, false}},
+		{name: "short last page", pages: []int{60, 40}, next: []bool{true, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pagination := NewPagination(config.PaginationConfig{
+				Strategy:   PaginationTotalCount,
+				TotalCount: "p.total",
+				PerPage:    test.perPage,
+			}, "")
+
+			for i, count := range test.pages {
+				if next := pagination.HasNextPage(doc, count, i+1); next != test.next[i] {
+					t.Errorf("page %d with %d products: expected next %v, got %v", i+1, count, test.next[i], next)
+				}
+			}
+		})
+	}
+}
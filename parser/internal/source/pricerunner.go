@@ -0,0 +1,620 @@
+package source
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/models"
+)
+
+// Запасные селекторы цены на странице товара.
+// XPath понимает только браузер, для статического HTML тот же поиск записан через :contains.
+const (
+	lowestPriceNowSelector       = `//p[contains(text(), "Lowest Price Now")]/following-sibling::span[@class="pr-1fcg5be"]`
+	lowestPriceNowStaticSelector = `p:contains("Lowest Price Now") ~ span.pr-1fcg5be`
+	priceStructureSelector       = `div.pr-1ymxntz div.pr-i5pc8s:nth-child(2) span.pr-1fcg5be`
+)
+
+// Запасные селекторы описания товара
+const (
+	descriptionItempropSelector = `[itemprop='description']`
+	descriptionMetaSelector     = `meta[name='description']`
+)
+
+// PriceRunner implements Source for pricerunner.com
+type PriceRunner struct {
+	selectors  config.SelectorsConfig
+	pagination Pagination
+}
+
+// NewPriceRunner creates a new PriceRunner source
+func NewPriceRunner(cfg config.ParserConfig) *PriceRunner {
+	return &PriceRunner{
+		selectors:  cfg.Selectors,
+		pagination: NewPagination(cfg.Pagination, cfg.Selectors.NextPageButton),
+	}
+}
+
+// Name returns the source identifier
+func (s *PriceRunner) Name() string {
+	return "pricerunner"
+}
+
+// Currency returns the currency of PriceRunner UK prices
+func (s *PriceRunner) Currency() string {
+	return "GBP"
+}
+
+// PageURL builds the URL of a category page
+func (s *PriceRunner) PageURL(baseURL string, pageNumber int) string {
+	return fmt.Sprintf("%s?page=%d", baseURL, pageNumber)
+}
+
+// ProductCardsSelector returns the product card selector
+func (s *PriceRunner) ProductCardsSelector() string {
+	return s.selectors.ProductCards
+}
+
+// ExtractProductCards extracts product information from page
+func (s *PriceRunner) ExtractProductCards(page fetch.Document) []models.BasicProduct {
+	cards, err := page.QuerySelectorAll(s.selectors.ProductCards)
+	if err != nil {
+		log.Printf("Error querying product cards: %v", err)
+		return nil
+	}
+
+	var products []models.BasicProduct
+
+	for _, card := range cards {
+		title, err := card.GetAttribute("title")
+		if err != nil || title == "" {
+			continue
+		}
+
+		href, err := card.GetAttribute("href")
+		if err != nil || href == "" {
+			continue
+		}
+
+		// Извлекаем ID из URL
+		re := regexp.MustCompile(`/pl/(\d+-\d+)/`)
+		matches := re.FindStringSubmatch(href)
+		if len(matches) < 2 {
+			continue
+		}
+
+		productID := matches[1]
+
+		// Формируем полный URL
+		fullURL := href
+		if !strings.HasPrefix(href, "http") {
+			fullURL = "https://www.pricerunner.com" + href
+		}
+
+		products = append(products, models.BasicProduct{
+			ID:    productID,
+			Title: title,
+			URL:   fullURL,
+		})
+	}
+
+	return products
+}
+
+// PrepareListPage loads all products of a list page for the load_more strategy
+func (s *PriceRunner) PrepareListPage(page fetch.Document) {
+	s.pagination.LoadMore(page, s.selectors.ProductCards)
+}
+
+// HasNextPage checks if there's a next page using the configured pagination strategy
+func (s *PriceRunner) HasNextPage(page fetch.Document, productsCount int, pageNumber int) bool {
+	log.Printf("  Checking if page %d has next page (%s)...", pageNumber, s.pagination.Strategy())
+	return s.pagination.HasNextPage(page, productsCount, pageNumber)
+}
+
+// PrepareDetailPage улучшенная прокрутка для загрузки всего контента.
+// Статический HTML уже содержит все, что отдал сервер.
+func (s *PriceRunner) PrepareDetailPage(doc fetch.Document) {
+	page := doc.Browser()
+	if page == nil {
+		return
+	}
+	log.Printf("  Loading all page content...")
+
+	// Стратегия: быстро прокрутить всю страницу для загрузки контента
+
+	// 1. Прокручиваем до конца страницы
+	page.Evaluate("window.scrollTo(0, document.body.scrollHeight)")
+	time.Sleep(1 * time.Second)
+
+	// 2. Проверяем есть ли таблицы
+	tablesCount := s.checkTablesCountQuick(doc)
+	log.Printf("    After full scroll: %d tables found", tablesCount)
+
+	// 3. Если таблиц мало, делаем дополнительные прокрутки
+	if tablesCount < 2 {
+		log.Printf("    Need more content, doing additional scrolling...")
+
+		// Прокручиваем к разным частям страницы
+		positions := []string{
+			"document.body.scrollHeight * 0.75", // 75%
+			"document.body.scrollHeight * 0.5",  // 50%
+			"document.body.scrollHeight * 0.25", // 25%
+		}
+
+		for _, pos := range positions {
+			page.Evaluate(fmt.Sprintf("window.scrollTo(0, %s)", pos))
+			time.Sleep(500 * time.Millisecond)
+		}
+
+		// Финальная прокрутка к концу
+		page.Evaluate("window.scrollTo(0, document.body.scrollHeight)")
+		time.Sleep(1 * time.Second)
+	}
+
+	// 4. Позиционируемся в середине для парсинга
+	page.Evaluate("window.scrollTo(0, document.body.scrollHeight / 2)")
+	time.Sleep(500 * time.Millisecond)
+
+	finalCount := s.checkTablesCountQuick(doc)
+	log.Printf("    Final: %d tables loaded", finalCount)
+}
+
+// ExtractDetail extracts all supported data from a product page
+func (s *PriceRunner) ExtractDetail(page fetch.Document) *Detail {
+	detail := &Detail{
+		OfferCount:  s.parseOfferCount(page),
+		ExtraImages: s.parseAdditionalImages(page),
+		Matched:     make(map[string]string),
+	}
+
+	detail.PriceText, detail.Matched[FieldPrice] = s.parsePrice(page)
+	detail.ImageURL, detail.Matched[FieldImage] = s.parseMainImage(page)
+	detail.Features, detail.Matched[FieldFeatures] = s.parseFeatures(page)
+	detail.Description, detail.Matched[FieldDescription] = s.parseDescription(page)
+
+	// Бренд и идентификаторы: сначала JSON-LD, затем таблица характеристик
+	detail.StructuredData = extractStructuredData(page)
+	applyStructuredData(detail)
+	applyFeatureIdentifiers(detail)
+
+	detail.Breadcrumbs = elementTexts(page, s.selectors.Breadcrumbs)
+	if len(detail.Breadcrumbs) == 0 {
+		detail.Breadcrumbs = extractBreadcrumbs(page)
+	}
+
+	return detail
+}
+
+// ExtractPrice extracts only the lowest price and offer count
+func (s *PriceRunner) ExtractPrice(page fetch.Document) (string, string) {
+	priceText, _ := s.parsePrice(page)
+	return priceText, s.parseOfferCount(page)
+}
+
+// ExtractOffers extracts the merchant offer list, falling back to JSON-LD offers
+func (s *PriceRunner) ExtractOffers(page fetch.Document) []Offer {
+	log.Printf("  Looking for merchant offers...")
+
+	offers := extractOfferRows(page, s.selectors.Offers, "https://www.pricerunner.com")
+	if len(offers) == 0 {
+		offers = offersFromStructuredData(extractStructuredData(page))
+	}
+
+	log.Printf("    Found %d offers", len(offers))
+	return offers
+}
+
+// Selectors returns all selectors the PriceRunner adapter depends on
+func (s *PriceRunner) Selectors() []SelectorCheck {
+	return []SelectorCheck{
+		{Name: "product_cards", Selector: s.selectors.ProductCards, Page: PageList},
+		{Name: "next_page_button", Selector: s.selectors.NextPageButton, Page: PageList},
+		{Name: "total_count", Selector: s.pagination.cfg.TotalCount, Page: PageList},
+		{Name: "load_more", Selector: s.pagination.cfg.LoadMore, Page: PageList},
+		{Name: "price", Selector: s.selectors.Price, Page: PageDetail},
+		// Самопроверка идет в браузере, поэтому проверяется XPath вариант
+		{Name: "price_lowest_now", Selector: lowestPriceNowSelector, Page: PageDetail},
+		{Name: "price_current", Selector: s.priceCurrentSelector(), Page: PageDetail},
+		{Name: "description", Selector: s.selectors.Description, Page: PageDetail},
+		{Name: "description_alt", Selector: s.selectors.DescriptionAlt, Page: PageDetail},
+		{Name: "main_image", Selector: s.selectors.MainImage, Page: PageDetail},
+		{Name: "additional_images", Selector: s.selectors.AdditionalImages, Page: PageDetail},
+		{Name: "feature_tables", Selector: s.selectors.FeatureTables, Page: PageDetail},
+		{Name: "offer_rows", Selector: s.selectors.Offers.Rows, Page: PageDetail},
+		{Name: "breadcrumbs", Selector: s.selectors.Breadcrumbs, Page: PageDetail},
+	}
+}
+
+// parseOfferCount ищет количество предложений в тексте страницы ("Compare 25 prices")
+func (s *PriceRunner) parseOfferCount(page fetch.Document) string {
+	bodyText, err := page.InnerText("body")
+	if err != nil {
+		return ""
+	}
+	return parseOfferCount(bodyText)
+}
+
+// checkTablesCountQuick быстрая проверка количества таблиц
+func (s *PriceRunner) checkTablesCountQuick(page fetch.Document) int {
+	// Пробуем основной селектор
+	tables, err := page.QuerySelectorAll(s.selectors.FeatureTables)
+	if err == nil && len(tables) > 0 {
+		return len(tables)
+	}
+
+	// Пробуем альтернативные селекторы
+	altSelectors := []string{
+		"table.pr-1regpt0-Table-table",
+		"div[class*='Table'] table",
+		"table",
+	}
+
+	maxCount := 0
+	for _, selector := range altSelectors {
+		tables, err := page.QuerySelectorAll(selector)
+		if err == nil && len(tables) > maxCount {
+			maxCount = len(tables)
+		}
+	}
+
+	return maxCount
+}
+
+// parsePrice parses price using the improved selector for second span.
+// Returns the price text and the selector that matched.
+func (s *PriceRunner) parsePrice(page fetch.Document) (string, string) {
+	log.Printf("    Looking for price (second span)...")
+
+	// Способ 1: Ищем все span'ы с нужным классом и берем второй
+	priceElements, err := page.QuerySelectorAll(s.selectors.Price)
+	if err != nil || len(priceElements) < 2 {
+		log.Printf("    Not enough price elements found (need 2, got %d)", len(priceElements))
+		return s.parsePriceFallback(page)
+	}
+
+	// Берем второй элемент (индекс 1)
+	secondPriceElement := priceElements[1]
+
+	priceText, err := secondPriceElement.InnerText()
+	if err != nil || priceText == "" {
+		log.Printf("    Failed to get text from second price element")
+		return s.parsePriceFallback(page)
+	}
+
+	priceText = strings.TrimSpace(priceText)
+	log.Printf("    Found price (second span): %s", priceText)
+
+	return priceText, s.selectors.Price
+}
+
+// parsePriceFallback - альтернативные способы поиска цены
+func (s *PriceRunner) parsePriceFallback(page fetch.Document) (string, string) {
+	log.Printf("    Trying fallback price selectors...")
+
+	// Способ 2: Ищем по тексту "Lowest Price Now"
+	lowestPriceNow := lowestPriceNowSelector
+	if page.Browser() == nil {
+		lowestPriceNow = lowestPriceNowStaticSelector
+	}
+	if priceElement, err := page.QuerySelector(lowestPriceNow); err == nil && priceElement != nil {
+		if priceText, err := priceElement.InnerText(); err == nil && priceText != "" {
+			log.Printf("    Found price via 'Lowest Price Now': %s", strings.TrimSpace(priceText))
+			return strings.TrimSpace(priceText), lowestPriceNow
+		}
+	}
+
+	// Способ 3: Ищем по структуре DOM - второй div с классом pr-i5pc8s
+	if priceElement, err := page.QuerySelector(s.priceCurrentSelector()); err == nil && priceElement != nil {
+		if priceText, err := priceElement.InnerText(); err == nil && priceText != "" {
+			log.Printf("    Found price via structure selector: %s", strings.TrimSpace(priceText))
+			return strings.TrimSpace(priceText), s.priceCurrentSelector()
+		}
+	}
+
+	log.Printf("    ⚠ No price found with any method")
+	return "", ""
+}
+
+// priceCurrentSelector returns the configured structure price selector or the built-in one
+func (s *PriceRunner) priceCurrentSelector() string {
+	if s.selectors.PriceCurrent != "" {
+		return s.selectors.PriceCurrent
+	}
+	return priceStructureSelector
+}
+
+// parseDescription parses the product description text and returns the selector that matched
+func (s *PriceRunner) parseDescription(page fetch.Document) (string, string) {
+	log.Printf("  Looking for description...")
+
+	description, selector := elementText(page,
+		s.selectors.Description,
+		s.selectors.DescriptionAlt,
+		s.selectors.DescriptionFallback,
+		descriptionItempropSelector,
+	)
+	if description != "" {
+		return description, selector
+	}
+
+	// Последний вариант - meta description
+	if meta, err := page.QuerySelector(descriptionMetaSelector); err == nil && meta != nil {
+		if content, _ := meta.GetAttribute("content"); strings.TrimSpace(content) != "" {
+			return strings.TrimSpace(content), descriptionMetaSelector
+		}
+	}
+
+	log.Printf("    ⚠ Description not found")
+	return "", ""
+}
+
+// parseMainImage parses the main product image URL and returns the selector that matched
+func (s *PriceRunner) parseMainImage(page fetch.Document) (string, string) {
+	log.Printf("  Looking for images...")
+
+	selectors := []string{
+		s.selectors.MainImage,
+		"picture.pr-lpjxdi source[type='image/jpeg']",
+		"img[itemprop='image']",
+		"div.pr-15dcama img",
+	}
+
+	for _, selector := range selectors {
+		element, err := page.QuerySelector(selector)
+		if err != nil || element == nil {
+			continue
+		}
+
+		srcset, _ := element.GetAttribute("srcset")
+		if srcset != "" {
+			urls := strings.Split(srcset, ",")
+			if len(urls) > 0 {
+				return strings.TrimSpace(strings.Fields(urls[len(urls)-1])[0]), selector
+			}
+		}
+
+		if strings.Contains(selector, "source") {
+			continue
+		}
+
+		src, _ := element.GetAttribute("src")
+		if src != "" && !strings.HasPrefix(src, "data:") {
+			return src, selector
+		}
+	}
+
+	log.Printf("    ⚠ Main image not found")
+	return "", ""
+}
+
+// parseAdditionalImages parses additional product image URLs
+func (s *PriceRunner) parseAdditionalImages(page fetch.Document) []string {
+	log.Printf("  Looking for additional images...")
+
+	thumbnails, err := page.QuerySelectorAll(s.selectors.AdditionalImages)
+	if err != nil {
+		return nil
+	}
+
+	var additionalImages []string
+	maxImages := 3 // Ограничиваем для скорости
+
+	for i, thumb := range thumbnails {
+		if i >= maxImages {
+			break
+		}
+
+		src, err := thumb.GetAttribute("src")
+		if err != nil || src == "" || strings.HasPrefix(src, "data:") {
+			continue
+		}
+
+		// Заменяем размер на больший
+		re := regexp.MustCompile(`/dim/dim/`)
+		additionalImages = append(additionalImages, re.ReplaceAllString(src, "/504x504/"))
+	}
+
+	return additionalImages
+}
+
+// parseFeatures парсинг таблицы с множественными заголовками внутри.
+// Возвращает характеристики и селектор таблиц, который их дал.
+func (s *PriceRunner) parseFeatures(page fetch.Document) (map[string]string, string) {
+	log.Printf("  Extracting features...")
+
+	features := make(map[string]string)
+
+	// Пробуем разные селекторы для таблиц
+	tableSelectors := []string{
+		s.selectors.FeatureTables,      // Основной
+		"table.pr-1regpt0-Table-table", // Альтернативный
+		"div[class*='Table'] table",    // По части класса
+		"table",                        // Любые таблицы
+	}
+
+	var tables []fetch.Element
+	var tablesSelector string
+
+	for _, selector := range tableSelectors {
+		foundTables, err := page.QuerySelectorAll(selector)
+		if err == nil && len(foundTables) > 0 {
+			tables = foundTables
+			tablesSelector = selector
+			log.Printf("    Found %d tables with selector: %s", len(foundTables), selector)
+			break
+		}
+	}
+
+	if len(tables) == 0 {
+		log.Printf("    ⚠ No feature tables found with any selector")
+		return features, ""
+	}
+
+	// Парсим каждую таблицу
+	for tableIdx, table := range tables {
+		log.Printf("    Processing table %d/%d", tableIdx+1, len(tables))
+
+		// Парсим таблицу с множественными заголовками
+		tableFeatures := s.parseTableWithMultipleHeaders(table)
+
+		// Объединяем с общим списком
+		for key, value := range tableFeatures {
+			features[key] = value
+		}
+	}
+
+	if len(features) == 0 {
+		log.Printf("    ⚠ No valid features extracted")
+		return features, ""
+	}
+
+	log.Printf("    ✓ Total features found: %d", len(features))
+	return features, tablesSelector
+}
+
+// parseTableWithMultipleHeaders разбирает таблицу, где заголовки групп чередуются со строками данных
+func (s *PriceRunner) parseTableWithMultipleHeaders(table fetch.Element) map[string]string {
+	features := make(map[string]string)
+
+	// Получаем все строки таблицы (и thead, и tbody)
+	allRows, err := table.QuerySelectorAll("tr")
+	if err != nil {
+		return features
+	}
+
+	currentCategory := "General"
+
+	for _, row := range allRows {
+		// Проверяем является ли строка заголовком
+		if s.isHeaderRow(row) {
+			// Это строка заголовка - извлекаем категорию
+			category := s.extractCategoryFromHeaderRow(row)
+			if category != "" {
+				currentCategory = category
+				log.Printf("      Found category header: '%s'", currentCategory)
+			}
+			continue
+		}
+
+		// Это строка данных - парсим характеристики
+		featureName, featureValue := s.extractFeatureFromRow(row)
+		if featureName != "" && featureValue != "" {
+			// Добавляем категорию к названию если она значимая
+			key := featureName
+			if currentCategory != "General" && currentCategory != "" {
+				key = fmt.Sprintf("%s: %s", currentCategory, featureName)
+			}
+
+			features[key] = featureValue
+		}
+	}
+
+	log.Printf("      Extracted %d features from table", len(features))
+	return features
+}
+
+// isHeaderRow проверяет является ли строка заголовком
+func (s *PriceRunner) isHeaderRow(row fetch.Element) bool {
+	// Способ 1: Проверяем родительский элемент (thead)
+	script := "\n\t\t\t\tlet row = arguments[0];\n\t\t\t\tlet parent = row.parentElement;\n\t\t\t\treturn parent && parent.tagName === 'THEAD';\n\t\t"
+	// В статическом HTML скрипты недоступны, строки thead обычно распознаются по th
+	if handle := fetch.Handle(row); handle != nil {
+		if result, err := handle.Evaluate(script); err == nil {
+			if isInThead, ok := result.(bool); ok && isInThead {
+				return true
+			}
+		}
+	}
+
+	// Способ 2: Проверяем наличие th вместо td
+	ths, err := row.QuerySelectorAll("th")
+	if err == nil && len(ths) > 0 {
+		return true
+	}
+
+	// Способ 3: Проверяем CSS класс или data-атрибуты
+	class, _ := row.GetAttribute("class")
+	dataKind, _ := row.GetAttribute("data-kind")
+
+	if strings.Contains(class, "heading") || strings.Contains(class, "header") ||
+		dataKind == "heading" || dataKind == "header" {
+		return true
+	}
+
+	// Способ 4: Проверяем ячейки с data-kind="heading"
+	cells, err := row.QuerySelectorAll("td, th")
+	if err == nil {
+		for _, cell := range cells {
+			if cellDataKind, _ := cell.GetAttribute("data-kind"); cellDataKind == "heading" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// extractCategoryFromHeaderRow извлекает название категории из строки заголовка
+func (s *PriceRunner) extractCategoryFromHeaderRow(row fetch.Element) string {
+	// Ищем div.pr-f8aw3g с текстом
+	if categoryDiv, err := row.QuerySelector("div.pr-f8aw3g"); err == nil && categoryDiv != nil {
+		if categoryText, err := categoryDiv.InnerText(); err == nil && categoryText != "" {
+			cleaned := strings.TrimSpace(categoryText)
+			if isValidCategory(cleaned) {
+				return cleaned
+			}
+		}
+	}
+
+	// Альтернативно - ищем в th
+	if th, err := row.QuerySelector("th"); err == nil && th != nil {
+		if categoryText, err := th.InnerText(); err == nil && categoryText != "" {
+			cleaned := strings.TrimSpace(categoryText)
+			if isValidCategory(cleaned) {
+				return cleaned
+			}
+		}
+	}
+
+	// Ищем в любой ячейке
+	cells, err := row.QuerySelectorAll("td, th")
+	if err == nil && len(cells) > 0 {
+		if categoryText, err := cells[0].InnerText(); err == nil && categoryText != "" {
+			cleaned := strings.TrimSpace(categoryText)
+			if isValidCategory(cleaned) {
+				return cleaned
+			}
+		}
+	}
+
+	return ""
+}
+
+// extractFeatureFromRow извлекает характеристику из строки данных
+func (s *PriceRunner) extractFeatureFromRow(row fetch.Element) (string, string) {
+	// Получаем ячейки td (не th)
+	cells, err := row.QuerySelectorAll("td")
+	if err != nil || len(cells) < 2 {
+		return "", ""
+	}
+
+	// Извлекаем название и значение
+	featureName, _ := cells[0].InnerText()
+	featureValue, _ := cells[1].InnerText()
+
+	featureName = strings.TrimSpace(featureName)
+	featureValue = strings.TrimSpace(featureValue)
+
+	// Валидация
+	if !isValidFeaturePair(featureName, featureValue) {
+		return "", ""
+	}
+
+	return featureName, featureValue
+}
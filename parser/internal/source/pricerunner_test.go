@@ -0,0 +1,32 @@
+package source
+
+import (
+	"testing"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+)
+
+func TestPriceRunnerStaticPriceFallback(t *testing.T) {
+	// Одна цена на странице: основной селектор (второй span) не срабатывает
+	const page = `<html><body>
+<div class="pr-summary">
+  <p>Lowest Price Now</p>
+  <span class="pr-1fcg5be">£599.00</span>
+</div>
+</body></html>`
+
+	doc, err := fetch.ParseHTML("https://www.pricerunner.com/pl/1-1/Phone", 200, []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewPriceRunner(config.ParserConfig{Selectors: config.SelectorsConfig{Price: "span.pr-1fcg5be"}})
+	price, selector := s.parsePrice(doc)
+	if price != "£599.00" {
+		t.Errorf("expected £599.00, got %q", price)
+	}
+	if selector != lowestPriceNowStaticSelector {
+		t.Errorf("expected the static Lowest Price Now selector, got %q", selector)
+	}
+}
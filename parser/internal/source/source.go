@@ -0,0 +1,174 @@
+package source
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/fetch"
+	"pricerunner-parser/internal/models"
+)
+
+// Source describes a site the parser can crawl
+type Source interface {
+	// Name returns the source identifier used in config and logs
+	Name() string
+
+	// Currency returns the ISO code of prices shown on the site
+	Currency() string
+
+	// PageURL builds the URL of a product list page
+	PageURL(baseURL string, pageNumber int) string
+
+	// ProductCardsSelector returns the selector that marks a loaded list page
+	ProductCardsSelector() string
+
+	// ExtractProductCards extracts basic product information from a list page
+	ExtractProductCards(page fetch.Document) []models.BasicProduct
+
+	// PrepareListPage loads all products of a list page, e.g. by clicking "load more"
+	PrepareListPage(page fetch.Document)
+
+	// HasNextPage checks if another list page follows the current one
+	HasNextPage(page fetch.Document, productsCount int, pageNumber int) bool
+
+	// PrepareDetailPage scrolls a detail page until its lazy content is loaded
+	PrepareDetailPage(page fetch.Document)
+
+	// ExtractDetail extracts price, images, features, description and identifiers from a detail page
+	ExtractDetail(page fetch.Document) *Detail
+
+	// ExtractPrice extracts only the current price and offer count from a detail page
+	ExtractPrice(page fetch.Document) (priceText string, offerCount string)
+
+	// ExtractOffers extracts the list of merchant offers from a detail page
+	ExtractOffers(page fetch.Document) []Offer
+
+	// Selectors returns all selectors the source depends on, for self-tests
+	Selectors() []SelectorCheck
+}
+
+// Page kinds a selector applies to
+const (
+	PageList   = "list"
+	PageDetail = "detail"
+)
+
+// Detail fields whose matching selector is reported in Detail.Matched
+const (
+	FieldPrice       = "price"
+	FieldImage       = "image"
+	FieldFeatures    = "features"
+	FieldDescription = "description"
+)
+
+// SelectorCheck describes a selector verified by the self-test
+type SelectorCheck struct {
+	Name     string `json:"name"`
+	Selector string `json:"selector"`
+	Page     string `json:"page"`
+}
+
+// Detail contains raw data extracted from a product detail page
+type Detail struct {
+	PriceText   string
+	OfferCount  string
+	ImageURL    string
+	ExtraImages []string
+	Features    map[string]string
+	Description string
+	Brand       string
+	Model       string
+	GTIN        string
+	MPN         string
+
+	// Breadcrumbs contains the category trail of the page from the root
+	Breadcrumbs []string
+
+	// StructuredData contains JSON-LD Product and Offer blocks found on the page
+	StructuredData []map[string]any
+
+	// Matched maps a field to the selector that produced it, empty if nothing matched
+	Matched map[string]string
+}
+
+// New creates a source instance based on configuration
+func New(cfg *config.Config) (Source, error) {
+	name := cfg.Parser.Source
+	if name == "pricerunner" {
+		return NewPriceRunner(cfg.Parser), nil
+	}
+
+	sourceCfg, ok := cfg.Parser.Sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported source: %s", name)
+	}
+
+	return NewDeclarative(name, sourceCfg)
+}
+
+// isValidCategory проверяет является ли строка валидной категорией
+func isValidCategory(category string) bool {
+	if len(category) < 2 || len(category) > 50 {
+		return false
+	}
+
+	// Исключаем пустые или служебные категории
+	excludes := []string{",", " ", "-", "–", "—", "N/A", "n/a", "TBD", "tbd"}
+	for _, exclude := range excludes {
+		if category == exclude {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isValidFeaturePair проверяет валидность пары название-значение
+func isValidFeaturePair(name, value string) bool {
+	if name == "" || value == "" {
+		return false
+	}
+
+	if name == value {
+		return false
+	}
+
+	if len(name) < 2 || len(value) < 1 {
+		return false
+	}
+
+	// Исключаем служебные строки
+	if strings.Contains(strings.ToLower(name), "compare") {
+		return false
+	}
+
+	return true
+}
+
+// offerCountPattern matches texts like "Compare 25 prices" or "12 shops"
+var offerCountPattern = regexp.MustCompile(`(?i)(\d+)\s+(?:prices|shops|stores|offers|merchants)`)
+
+// parseOfferCount extracts the number of offers from a text
+func parseOfferCount(text string) string {
+	matches := offerCountPattern.FindStringSubmatch(text)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// resolveURL resolves a possibly relative link against the site URL
+func resolveURL(siteURL, href string) string {
+	base, err := url.Parse(siteURL)
+	if err != nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
@@ -0,0 +1,296 @@
+package source
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pricerunner-parser/internal/fetch"
+)
+
+// jsonLDSelector matches embedded schema.org structured data
+const jsonLDSelector = `script[type="application/ld+json"]`
+
+// structuredTypes are the schema.org types kept from JSON-LD blocks
+var structuredTypes = map[string]bool{
+	"Product":        true,
+	"ProductGroup":   true,
+	"Offer":          true,
+	"AggregateOffer": true,
+}
+
+// gtinKeys are the schema.org GTIN properties in order of preference
+var gtinKeys = []string{"gtin13", "gtin", "gtin14", "gtin12", "gtin8", "isbn"}
+
+// nonDigitPattern matches everything except digits in identifiers
+var nonDigitPattern = regexp.MustCompile(`\D`)
+
+// extractStructuredData returns all JSON-LD Product and Offer blocks of a page
+func extractStructuredData(page fetch.Node) []map[string]any {
+	return extractJSONLD(page, structuredTypes)
+}
+
+// extractBreadcrumbs returns the names of a JSON-LD BreadcrumbList in position order
+func extractBreadcrumbs(page fetch.Node) []string {
+	blocks := extractJSONLD(page, map[string]bool{"BreadcrumbList": true})
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	items, _ := blocks[0]["itemListElement"].([]any)
+	type crumb struct {
+		position float64
+		name     string
+	}
+	var crumbs []crumb
+	for _, item := range items {
+		element, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name := stringProperty(element["name"])
+		if name == "" {
+			name = stringProperty(element["item"])
+		}
+		position, _ := element["position"].(float64)
+		if name != "" {
+			crumbs = append(crumbs, crumb{position: position, name: name})
+		}
+	}
+	sort.SliceStable(crumbs, func(i, j int) bool { return crumbs[i].position < crumbs[j].position })
+
+	names := make([]string, 0, len(crumbs))
+	for _, c := range crumbs {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// extractJSONLD returns JSON-LD blocks of the given schema.org types
+func extractJSONLD(page fetch.Node, types map[string]bool) []map[string]any {
+	scripts, err := page.QuerySelectorAll(jsonLDSelector)
+	if err != nil {
+		return nil
+	}
+
+	var blocks []map[string]any
+	for _, script := range scripts {
+		text, err := script.TextContent()
+		if err != nil || strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		var data any
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			continue // Битый JSON-LD встречается часто, просто пропускаем
+		}
+
+		blocks = append(blocks, collectStructuredBlocks(data, types)...)
+	}
+
+	return blocks
+}
+
+// collectStructuredBlocks walks arrays and @graph containers and keeps blocks of the given types
+func collectStructuredBlocks(data any, types map[string]bool) []map[string]any {
+	var blocks []map[string]any
+
+	switch value := data.(type) {
+	case []any:
+		for _, item := range value {
+			blocks = append(blocks, collectStructuredBlocks(item, types)...)
+		}
+	case map[string]any:
+		if graph, ok := value["@graph"]; ok {
+			blocks = append(blocks, collectStructuredBlocks(graph, types)...)
+		}
+		for _, schemaType := range schemaTypes(value) {
+			if types[schemaType] {
+				blocks = append(blocks, value)
+				break
+			}
+		}
+	}
+
+	return blocks
+}
+
+// schemaTypes returns the @type of a block, which may be a string or a list
+func schemaTypes(block map[string]any) []string {
+	switch value := block["@type"].(type) {
+	case string:
+		return []string{schemaTypeName(value)}
+	case []any:
+		var types []string
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				types = append(types, schemaTypeName(text))
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// schemaTypeName strips the schema.org prefix from a full type URL
+func schemaTypeName(schemaType string) string {
+	schemaType = strings.TrimPrefix(schemaType, "https://schema.org/")
+	return strings.TrimPrefix(schemaType, "http://schema.org/")
+}
+
+// applyStructuredData fills description and identifiers missing in the detail from JSON-LD Product blocks
+func applyStructuredData(detail *Detail) {
+	for _, block := range detail.StructuredData {
+		isProduct := false
+		for _, schemaType := range schemaTypes(block) {
+			if schemaType == "Product" || schemaType == "ProductGroup" {
+				isProduct = true
+			}
+		}
+		if !isProduct {
+			continue
+		}
+
+		if detail.Description == "" {
+			detail.Description = strings.TrimSpace(stringProperty(block["description"]))
+		}
+		if detail.Brand == "" {
+			detail.Brand = stringProperty(block["brand"])
+		}
+		if detail.Model == "" {
+			detail.Model = stringProperty(block["model"])
+		}
+		if detail.MPN == "" {
+			detail.MPN = stringProperty(block["mpn"])
+		}
+		if detail.GTIN == "" {
+			for _, key := range gtinKeys {
+				if gtin := normalizeGTIN(stringProperty(block[key])); gtin != "" {
+					detail.GTIN = gtin
+					break
+				}
+			}
+		}
+	}
+}
+
+// applyFeatureIdentifiers fills brand and identifiers missing in the detail from specification rows
+func applyFeatureIdentifiers(detail *Detail) {
+	for key, value := range detail.Features {
+		// Ключи вида "General: Brand" - берем название после категории
+		name := key
+		if idx := strings.LastIndex(key, ": "); idx >= 0 {
+			name = key[idx+2:]
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "brand", "manufacturer":
+			if detail.Brand == "" {
+				detail.Brand = value
+			}
+		case "model", "model number", "model name":
+			if detail.Model == "" {
+				detail.Model = value
+			}
+		case "ean", "gtin", "upc", "ean/upc", "barcode":
+			if detail.GTIN == "" {
+				detail.GTIN = normalizeGTIN(value)
+			}
+		case "mpn", "manufacturer part number", "part number":
+			if detail.MPN == "" {
+				detail.MPN = value
+			}
+		}
+	}
+}
+
+// stringProperty returns a JSON-LD property that is either text or an object with a name
+func stringProperty(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return strings.TrimSpace(typed)
+	case float64:
+		// GTIN иногда указывают числом
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case map[string]any:
+		return stringProperty(typed["name"])
+	case []any:
+		if len(typed) > 0 {
+			return stringProperty(typed[0])
+		}
+	}
+	return ""
+}
+
+// numberProperty returns a JSON-LD numeric property given as a number or as text with a decimal point,
+// 0 if it is missing or not a plain number
+func numberProperty(value any) float64 {
+	switch typed := value.(type) {
+	case float64:
+		return typed
+	case string:
+		// schema.org требует точку как десятичный разделитель
+		if number, err := strconv.ParseFloat(strings.TrimSpace(typed), 64); err == nil {
+			return number
+		}
+	}
+	return 0
+}
+
+// normalizeGTIN keeps digits only and accepts valid GTIN-8/12/13/14 lengths
+func normalizeGTIN(value string) string {
+	digits := nonDigitPattern.ReplaceAllString(value, "")
+	switch len(digits) {
+	case 8, 12, 13, 14:
+		return digits
+	default:
+		return ""
+	}
+}
+
+// elementText returns the trimmed inner text of the first element matching one of the selectors.
+// It also returns the selector that matched.
+func elementText(page fetch.Document, selectors ...string) (string, string) {
+	for _, selector := range selectors {
+		if selector == "" {
+			continue
+		}
+
+		element, err := page.QuerySelector(selector)
+		if err != nil || element == nil {
+			continue
+		}
+
+		text, err := element.InnerText()
+		if err != nil {
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			return text, selector
+		}
+	}
+	return "", ""
+}
+
+// elementTexts returns the trimmed inner texts of all elements matching selector
+func elementTexts(root fetch.Node, selector string) []string {
+	if selector == "" {
+		return nil
+	}
+
+	elements, err := root.QuerySelectorAll(selector)
+	if err != nil {
+		return nil
+	}
+
+	var texts []string
+	for _, element := range elements {
+		if text, err := element.InnerText(); err == nil && strings.TrimSpace(text) != "" {
+			texts = append(texts, strings.TrimSpace(text))
+		}
+	}
+	return texts
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"pricerunner-parser/internal/config"
 	"pricerunner-parser/internal/models"
@@ -59,10 +60,50 @@ func (d *DatabaseStorage) createTables() error {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS price_original VARCHAR(30)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS currency VARCHAR(3)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS price_updated_at TIMESTAMP`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS previous_price_eur DECIMAL(10,2)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS previous_price_original VARCHAR(30)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS previous_price_at TIMESTAMP`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS description TEXT`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS brand TEXT`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS model TEXT`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS gtin VARCHAR(14)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS mpn TEXT`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS structured_data JSONB`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS category_path TEXT`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS image_hash VARCHAR(64)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS group_id VARCHAR(32)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS variant JSONB`,
+		`CREATE TABLE IF NOT EXISTS images (
+			hash VARCHAR(64) PRIMARY KEY,
+			mime_type VARCHAR(30),
+			width INTEGER,
+			height INTEGER,
+			size_bytes BIGINT,
+			source_url TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS offers (
+			id SERIAL PRIMARY KEY,
+			product_id VARCHAR(50) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			merchant TEXT NOT NULL,
+			price DECIMAL(10,2),
+			currency VARCHAR(3),
+			price_eur DECIMAL(10,2),
+			shipping TEXT,
+			in_stock BOOLEAN DEFAULT TRUE,
+			url TEXT,
+			scraped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_offers_product_id ON offers(product_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_products_created_at ON products(created_at)`,
 		`CREATE INDEX IF NOT EXISTS idx_products_price_eur ON products(price_eur)`,
 		`CREATE INDEX IF NOT EXISTS idx_products_title ON products USING gin(to_tsvector('english', title))`,
 		`CREATE INDEX IF NOT EXISTS idx_products_google_id ON products(google_product_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_products_gtin ON products(gtin)`,
+		`CREATE INDEX IF NOT EXISTS idx_products_group_id ON products(group_id)`,
 	}
 
 	for _, query := range queries {
@@ -91,9 +132,18 @@ func (d *DatabaseStorage) SaveProducts(products []models.Product, pageNumber int
 		INSERT INTO products (id, title, url, image_url, image_local, 
 			price_gbp, price_eur, offer_count, 
 			features, category, additional_images, google_product_id,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			created_at, updated_at, price_original, currency, price_updated_at,
+			description, brand, model, gtin, mpn, structured_data, category_path, image_hash,
+			group_id, variant
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $14,
+			$17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
 		ON CONFLICT (id) DO UPDATE SET
+			previous_price_eur = CASE WHEN products.price_eur IS DISTINCT FROM EXCLUDED.price_eur
+				THEN products.price_eur ELSE products.previous_price_eur END,
+			previous_price_original = CASE WHEN products.price_eur IS DISTINCT FROM EXCLUDED.price_eur
+				THEN products.price_original ELSE products.previous_price_original END,
+			previous_price_at = CASE WHEN products.price_eur IS DISTINCT FROM EXCLUDED.price_eur
+				THEN COALESCE(products.price_updated_at, products.updated_at) ELSE products.previous_price_at END,
 			title = EXCLUDED.title,
 			url = EXCLUDED.url,
 			image_url = EXCLUDED.image_url,
@@ -104,8 +154,21 @@ func (d *DatabaseStorage) SaveProducts(products []models.Product, pageNumber int
 			features = EXCLUDED.features,
 			category = EXCLUDED.category,
 			additional_images = EXCLUDED.additional_images,
-			google_product_id = EXCLUDED.google_product_id,
-			updated_at = EXCLUDED.updated_at
+			google_product_id = COALESCE(NULLIF(EXCLUDED.google_product_id, ''), products.google_product_id),
+			updated_at = EXCLUDED.updated_at,
+			price_original = EXCLUDED.price_original,
+			currency = EXCLUDED.currency,
+			price_updated_at = EXCLUDED.price_updated_at,
+			description = EXCLUDED.description,
+			brand = EXCLUDED.brand,
+			model = EXCLUDED.model,
+			gtin = EXCLUDED.gtin,
+			mpn = EXCLUDED.mpn,
+			structured_data = EXCLUDED.structured_data,
+			category_path = EXCLUDED.category_path,
+			image_hash = EXCLUDED.image_hash,
+			group_id = EXCLUDED.group_id,
+			variant = EXCLUDED.variant
 	`
 
 	stmt, err := tx.Prepare(query)
@@ -118,13 +181,19 @@ func (d *DatabaseStorage) SaveProducts(products []models.Product, pageNumber int
 		// Конвертируем сложные поля в JSON
 		featuresJSON, _ := json.Marshal(product.Features)
 		additionalImagesJSON, _ := json.Marshal(product.ExtraImages)
+		structuredDataJSON, _ := json.Marshal(product.StructuredData)
+		variantJSON, _ := json.Marshal(product.Variant)
 
 		var priceGBP string
 		var priceEUR sql.NullFloat64
 		var offerCount string
+		var priceOriginal string
+		var currency string
 
 		if product.Price != nil {
 			priceGBP = product.Price.PriceGBP
+			priceOriginal = product.Price.PriceOriginal
+			currency = product.Price.Currency
 			if product.Price.PriceEUR > 0 {
 				priceEUR = sql.NullFloat64{Float64: product.Price.PriceEUR, Valid: true}
 			}
@@ -143,23 +212,188 @@ func (d *DatabaseStorage) SaveProducts(products []models.Product, pageNumber int
 			featuresJSON,
 			product.Category,
 			additionalImagesJSON,
-			"", // google_product_id пока пустой
+			"", // google_product_id пока пустой, сохраненный ID не затирается
 			product.CreatedAt,
 			product.UpdatedAt,
+			priceOriginal,
+			currency,
+			product.Description,
+			product.Brand,
+			product.Model,
+			product.GTIN,
+			product.MPN,
+			structuredDataJSON,
+			product.CategoryPath,
+			product.ImageHash,
+			product.GroupID,
+			variantJSON,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert product %s: %w", product.ID, err)
 		}
+
+		if err := replaceOffers(tx, product.ID, product.Offers); err != nil {
+			return err
+		}
+
+		if err := saveImages(tx, product); err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit()
 }
 
+// saveImages records hash, type and dimensions of the stored images of a product
+func saveImages(tx *sql.Tx, product models.Product) error {
+	images := product.ExtraImages
+	if product.Image != nil {
+		images = append([]models.ImageInfo{*product.Image}, images...)
+	}
+
+	for _, image := range images {
+		if image.Hash == "" {
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO images (hash, mime_type, width, height, size_bytes, source_url)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (hash) DO NOTHING`,
+			image.Hash, image.MIME, image.Width, image.Height, image.Bytes, image.URL,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save image %s: %w", image.Hash, err)
+		}
+	}
+
+	return nil
+}
+
+// replaceOffers replaces the stored merchant offers of a product with freshly scraped ones.
+// Stored offers are kept if nothing was scraped, so a broken selector does not wipe them.
+func replaceOffers(tx *sql.Tx, productID string, offers []models.Offer) error {
+	if len(offers) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(`DELETE FROM offers WHERE product_id = $1`, productID); err != nil {
+		return fmt.Errorf("failed to delete offers of product %s: %w", productID, err)
+	}
+
+	for _, offer := range offers {
+		var priceEUR sql.NullFloat64
+		if offer.PriceEUR > 0 {
+			priceEUR = sql.NullFloat64{Float64: offer.PriceEUR, Valid: true}
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO offers (product_id, merchant, price, currency, price_eur, shipping, in_stock, url, scraped_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			productID, offer.Merchant, offer.Price, offer.Currency, priceEUR,
+			offer.Shipping, offer.InStock, offer.URL, offer.ScrapedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert offer of product %s: %w", productID, err)
+		}
+	}
+
+	return nil
+}
+
 // SaveFinalData для БД это то же самое что и SaveProducts
 func (d *DatabaseStorage) SaveFinalData(products []models.Product) error {
 	return d.SaveProducts(products, 0)
 }
 
+// UpdatePrices updates current prices and keeps the previous price with its timestamp
+func (d *DatabaseStorage) UpdatePrices(updates []models.PriceUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Предыдущая цена сохраняется только если цена изменилась
+	query := `
+		UPDATE products SET
+			previous_price_eur = CASE WHEN price_eur IS DISTINCT FROM $2
+				THEN price_eur ELSE previous_price_eur END,
+			previous_price_original = CASE WHEN price_eur IS DISTINCT FROM $2
+				THEN price_original ELSE previous_price_original END,
+			previous_price_at = CASE WHEN price_eur IS DISTINCT FROM $2
+				THEN COALESCE(price_updated_at, updated_at) ELSE previous_price_at END,
+			price_eur = $2,
+			price_gbp = $3,
+			price_original = $4,
+			currency = $5,
+			offer_count = COALESCE(NULLIF($6, ''), offer_count),
+			price_updated_at = $7
+		WHERE id = $1
+	`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, update := range updates {
+		if update.Price == nil {
+			continue
+		}
+
+		var priceEUR sql.NullFloat64
+		if update.Price.PriceEUR > 0 {
+			priceEUR = sql.NullFloat64{Float64: update.Price.PriceEUR, Valid: true}
+		}
+
+		_, err := stmt.Exec(
+			update.ID,
+			priceEUR,
+			update.Price.PriceGBP,
+			update.Price.PriceOriginal,
+			update.Price.Currency,
+			update.Price.OfferCount,
+			update.CheckedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update price of product %s: %w", update.ID, err)
+		}
+
+		if err := replaceOffers(tx, update.ID, update.Offers); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetProductUpdateTimes returns the time of the last full parse for each product
+func (d *DatabaseStorage) GetProductUpdateTimes() (map[string]time.Time, error) {
+	rows, err := d.db.Query("SELECT id, updated_at FROM products")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product update times: %w", err)
+	}
+	defer rows.Close()
+
+	updateTimes := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&id, &updatedAt); err != nil {
+			continue
+		}
+		updateTimes[id] = updatedAt.Time
+	}
+
+	return updateTimes, nil
+}
+
 // ProductExists checks if a product exists in database
 func (d *DatabaseStorage) ProductExists(productID string) (bool, error) {
 	var exists bool
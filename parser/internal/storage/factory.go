@@ -5,14 +5,38 @@ import (
 	"pricerunner-parser/internal/config"
 )
 
-// NewStorage creates a storage instance based on configuration
+// NewStorage creates a storage instance based on configuration.
+// If several backends are listed, writes go to all of them and reads to the first one.
 func NewStorage(cfg *config.Config) (Storage, error) {
-	switch cfg.Storage.Type {
+	if len(cfg.Storage.Backends) == 0 {
+		return newBackend(cfg, cfg.Storage.Type)
+	}
+
+	var backends []Storage
+	for _, storageType := range cfg.Storage.Backends {
+		backend, err := newBackend(cfg, storageType)
+		if err != nil {
+			for _, created := range backends {
+				created.Close()
+			}
+			return nil, err
+		}
+		backends = append(backends, backend)
+	}
+
+	return NewMultiStorage(backends...), nil
+}
+
+// newBackend creates a single storage backend by type
+func newBackend(cfg *config.Config, storageType string) (Storage, error) {
+	switch storageType {
 	case "json":
 		return NewJSONStorage(cfg.Storage.OutputDir), nil
 	case "database":
 		return NewDatabaseStorage(cfg.Storage.Database)
+	case "meilisearch":
+		return NewMeiliSearchStorage(cfg.Storage.MeiliSearch)
 	default:
-		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Storage.Type)
+		return nil, fmt.Errorf("unsupported storage type: %s", storageType)
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"pricerunner-parser/internal/models"
 )
@@ -94,17 +95,12 @@ func (j *JSONStorage) GetExistingProducts() ([]string, error) {
 	var allIDs []string
 
 	// Читаем все JSON файлы в директории
-	files, err := filepath.Glob(filepath.Join(j.outputDir, "*.json"))
+	files, err := j.productFiles()
 	if err != nil {
-		return nil, fmt.Errorf("failed to glob JSON files: %w", err)
+		return nil, err
 	}
 
 	for _, file := range files {
-		// Пропускаем системные файлы
-		if strings.Contains(filepath.Base(file), "temp") {
-			continue
-		}
-
 		products, err := j.readProductsFromFile(file)
 		if err != nil {
 			continue // Пропускаем файлы с ошибками
@@ -118,6 +114,135 @@ func (j *JSONStorage) GetExistingProducts() ([]string, error) {
 	return allIDs, nil
 }
 
+// GetProductUpdateTimes returns the latest full parse time for each product in JSON files
+func (j *JSONStorage) GetProductUpdateTimes() (map[string]time.Time, error) {
+	files, err := j.productFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	updateTimes := make(map[string]time.Time)
+	for _, file := range files {
+		products, err := j.readProductsFromFile(file)
+		if err != nil {
+			continue
+		}
+
+		for _, product := range products {
+			if product.UpdatedAt.After(updateTimes[product.ID]) {
+				updateTimes[product.ID] = product.UpdatedAt
+			}
+		}
+	}
+
+	return updateTimes, nil
+}
+
+// UpdatePrices rewrites prices of known products in every JSON file that contains them
+func (j *JSONStorage) UpdatePrices(updates []models.PriceUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updatesByID := make(map[string]models.PriceUpdate)
+	for _, update := range updates {
+		if update.Price != nil {
+			updatesByID[update.ID] = update
+		}
+	}
+
+	files, err := j.productFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		products, err := j.readProductsFromFile(file)
+		if err != nil {
+			continue
+		}
+
+		changed := false
+		for i := range products {
+			update, ok := updatesByID[products[i].ID]
+			if !ok {
+				continue
+			}
+			applyPriceUpdate(&products[i], update)
+			changed = true
+		}
+
+		if changed {
+			if err := j.writeProductsToFile(file, products); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyPriceUpdate sets the new price and keeps the previous one if it changed
+func applyPriceUpdate(product *models.Product, update models.PriceUpdate) {
+	if product.Price != nil && product.Price.PriceEUR != update.Price.PriceEUR {
+		previousAt := product.UpdatedAt
+		if product.PriceUpdatedAt != nil {
+			previousAt = *product.PriceUpdatedAt
+		}
+		product.PreviousPrice = product.Price
+		product.PreviousPriceAt = &previousAt
+	}
+
+	price := *update.Price
+	if price.OfferCount == "" && product.Price != nil {
+		price.OfferCount = product.Price.OfferCount
+	}
+	checkedAt := update.CheckedAt
+	product.Price = &price
+	product.PriceUpdatedAt = &checkedAt
+	if len(update.Offers) > 0 {
+		product.Offers = update.Offers
+	}
+}
+
+// productFiles returns all product JSON files in the output directory
+func (j *JSONStorage) productFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(j.outputDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob JSON files: %w", err)
+	}
+
+	var productFiles []string
+	for _, file := range files {
+		// Пропускаем системные файлы
+		if strings.Contains(filepath.Base(file), "temp") {
+			continue
+		}
+		productFiles = append(productFiles, file)
+	}
+
+	return productFiles, nil
+}
+
+// writeProductsToFile writes products to a specific JSON file
+func (j *JSONStorage) writeProductsToFile(filePath string, products []models.Product) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(products); err != nil {
+		return fmt.Errorf("failed to encode products: %w", err)
+	}
+
+	return nil
+}
+
 // readProductsFromFile reads products from a specific JSON file
 func (j *JSONStorage) readProductsFromFile(filePath string) ([]models.Product, error) {
 	file, err := os.Open(filePath)
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/models"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// meiliPageSize is the number of documents fetched per request when listing the index
+const meiliPageSize = 1000
+
+// meiliDocument mirrors the product document shape used by the backend search.
+// MerchantCount is the numeric offer count used by range filters and facets.
+type meiliDocument struct {
+	ID            string          `json:"id"`
+	Title         string          `json:"title"`
+	Category      string          `json:"category"`
+	CategoryPath  string          `json:"category_path,omitempty"`
+	Features      []string        `json:"features"`
+	ImageURL      string          `json:"image_url"`
+	ImageHash     string          `json:"image_hash,omitempty"`
+	URL           string          `json:"url,omitempty"`
+	Description   string          `json:"description,omitempty"`
+	Brand         string          `json:"brand,omitempty"`
+	Model         string          `json:"model,omitempty"`
+	GTIN          string          `json:"gtin,omitempty"`
+	MPN           string          `json:"mpn,omitempty"`
+	PriceEUR      float64         `json:"price_eur,omitempty"`
+	OfferCount    string          `json:"offer_count,omitempty"`
+	MerchantCount int             `json:"merchant_count,omitempty"`
+	UpdatedAt     int64           `json:"updated_at,omitempty"`
+	GroupID       string          `json:"group_id,omitempty"`
+	Variant       *models.Variant `json:"variant,omitempty"`
+	Lang          string          `json:"lang,omitempty"`
+	Region        string          `json:"region,omitempty"`
+}
+
+// meiliPriceDocument is a partial document used to refresh prices only
+type meiliPriceDocument struct {
+	ID            string  `json:"id"`
+	PriceEUR      float64 `json:"price_eur,omitempty"`
+	OfferCount    string  `json:"offer_count,omitempty"`
+	MerchantCount int     `json:"merchant_count,omitempty"`
+}
+
+// MeiliSearchStorage implements Storage interface for the Meilisearch products index
+type MeiliSearchStorage struct {
+	client meilisearch.ServiceManager
+	index  string
+}
+
+// NewMeiliSearchStorage creates a new Meilisearch storage instance
+func NewMeiliSearchStorage(cfg config.MeiliSearchConfig) (*MeiliSearchStorage, error) {
+	var options []meilisearch.Option
+	if cfg.APIKey != "" {
+		options = append(options, meilisearch.WithAPIKey(cfg.APIKey))
+	}
+
+	client := meilisearch.New(cfg.Host, options...)
+	// Meilisearch может подниматься дольше парсера: не падаем, а пишем страницы с ошибкой, пока он недоступен
+	if !waitHealthy(client) {
+		log.Printf("Warning: meilisearch at %s is not healthy, writes will fail until it is up", cfg.Host)
+	}
+
+	index := cfg.Index
+	if index == "" {
+		index = "products"
+	}
+
+	return &MeiliSearchStorage{
+		client: client,
+		index:  index,
+	}, nil
+}
+
+// meiliHealthAttempts and meiliHealthBackoff bound the startup health probe, about 15 seconds in total
+const (
+	meiliHealthAttempts = 5
+	meiliHealthBackoff  = time.Second
+)
+
+// waitHealthy probes Meilisearch with exponential backoff and reports whether it became healthy
+func waitHealthy(client meilisearch.ServiceManager) bool {
+	delay := meiliHealthBackoff
+	for attempt := 1; ; attempt++ {
+		if client.IsHealthy() {
+			return true
+		}
+		if attempt == meiliHealthAttempts {
+			return false
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// SaveProducts adds or updates product documents in the index.
+// Documents are merged, so fields set by the backend (google_product_id) are kept.
+func (m *MeiliSearchStorage) SaveProducts(products []models.Product, pageNumber int) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	documents := make([]meiliDocument, 0, len(products))
+	for _, product := range products {
+		documents = append(documents, toMeiliDocument(product))
+	}
+
+	primaryKey := "id"
+	if _, err := m.client.Index(m.index).UpdateDocuments(documents, &primaryKey); err != nil {
+		return fmt.Errorf("failed to update documents: %w", err)
+	}
+
+	return nil
+}
+
+// SaveFinalData is a no-op because documents are pushed page by page
+func (m *MeiliSearchStorage) SaveFinalData(products []models.Product) error {
+	return nil
+}
+
+// UpdatePrices updates prices of known product documents
+func (m *MeiliSearchStorage) UpdatePrices(updates []models.PriceUpdate) error {
+	var documents []meiliPriceDocument
+	for _, update := range updates {
+		if update.Price == nil {
+			continue
+		}
+		documents = append(documents, meiliPriceDocument{
+			ID:            update.ID,
+			PriceEUR:      update.Price.PriceEUR,
+			OfferCount:    update.Price.OfferCount,
+			MerchantCount: merchantCount(update.Offers, update.Price.OfferCount),
+		})
+	}
+
+	if len(documents) == 0 {
+		return nil
+	}
+
+	primaryKey := "id"
+	if _, err := m.client.Index(m.index).UpdateDocuments(documents, &primaryKey); err != nil {
+		return fmt.Errorf("failed to update prices: %w", err)
+	}
+
+	return nil
+}
+
+// ProductExists checks if a product document exists in the index
+func (m *MeiliSearchStorage) ProductExists(productID string) (bool, error) {
+	var document meiliDocument
+	err := m.client.Index(m.index).GetDocument(productID, &meilisearch.DocumentQuery{Fields: []string{"id"}}, &document)
+	if err != nil {
+		if meiliErr, ok := err.(*meilisearch.Error); ok && meiliErr.StatusCode == 404 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get document: %w", err)
+	}
+	return true, nil
+}
+
+// GetExistingProducts returns all product IDs in the index
+func (m *MeiliSearchStorage) GetExistingProducts() ([]string, error) {
+	updateTimes, err := m.GetProductUpdateTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]string, 0, len(updateTimes))
+	for id := range updateTimes {
+		productIDs = append(productIDs, id)
+	}
+	sort.Strings(productIDs)
+
+	return productIDs, nil
+}
+
+// GetProductUpdateTimes returns the last parse time stored in each document
+func (m *MeiliSearchStorage) GetProductUpdateTimes() (map[string]time.Time, error) {
+	updateTimes := make(map[string]time.Time)
+
+	for offset := int64(0); ; offset += meiliPageSize {
+		var result meilisearch.DocumentsResult
+		err := m.client.Index(m.index).GetDocuments(&meilisearch.DocumentsQuery{
+			Offset: offset,
+			Limit:  meiliPageSize,
+			Fields: []string{"id", "updated_at"},
+		}, &result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get documents: %w", err)
+		}
+
+		var documents []meiliDocument
+		if err := result.Results.Decode(&documents); err != nil {
+			return nil, fmt.Errorf("failed to decode documents: %w", err)
+		}
+
+		for _, document := range documents {
+			updateTimes[document.ID] = time.Unix(document.UpdatedAt, 0)
+		}
+
+		if offset+meiliPageSize >= result.Total {
+			break
+		}
+	}
+
+	return updateTimes, nil
+}
+
+// Close implements Storage interface (no-op for Meilisearch)
+func (m *MeiliSearchStorage) Close() error {
+	return nil
+}
+
+// toMeiliDocument maps a parsed product to the backend document shape
+func toMeiliDocument(product models.Product) meiliDocument {
+	document := meiliDocument{
+		ID:           product.ID,
+		Title:        product.Title,
+		Category:     product.Category,
+		CategoryPath: product.CategoryPath,
+		Features:     make([]string, 0, len(product.Features)),
+		ImageURL:     product.ImageURL,
+		ImageHash:    product.ImageHash,
+		URL:          product.URL,
+		Description:  product.Description,
+		Brand:        product.Brand,
+		Model:        product.Model,
+		GTIN:         product.GTIN,
+		MPN:          product.MPN,
+		GroupID:      product.GroupID,
+		Variant:      product.Variant,
+		Lang:         product.Lang,
+		Region:       product.Region,
+	}
+
+	// Нулевое время дало бы отрицательную метку, поле просто не пишется
+	if !product.UpdatedAt.IsZero() {
+		document.UpdatedAt = product.UpdatedAt.Unix()
+	}
+
+	// Backend ищет по признакам в виде "name: value"
+	for name, value := range product.Features {
+		document.Features = append(document.Features, fmt.Sprintf("%s: %s", name, value))
+	}
+	sort.Strings(document.Features)
+
+	if product.Price != nil {
+		document.PriceEUR = product.Price.PriceEUR
+		document.OfferCount = product.Price.OfferCount
+		document.MerchantCount = merchantCount(product.Offers, product.Price.OfferCount)
+	}
+
+	return document
+}
+
+// merchantCount returns the number of scraped offers or the count shown on the page
+func merchantCount(offers []models.Offer, offerCount string) int {
+	if len(offers) > 0 {
+		return len(offers)
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(offerCount))
+	return count
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"pricerunner-parser/internal/models"
+)
+
+func TestToMeiliDocumentUpdatedAt(t *testing.T) {
+	data, err := json.Marshal(toMeiliDocument(models.Product{ID: "1", Title: "Apple iPhone 15"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "updated_at") {
+		t.Errorf("zero UpdatedAt should be omitted: %s", data)
+	}
+
+	updatedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	document := toMeiliDocument(models.Product{ID: "1", Title: "Apple iPhone 15", UpdatedAt: updatedAt})
+	if document.UpdatedAt != updatedAt.Unix() {
+		t.Errorf("expected %d, got %d", updatedAt.Unix(), document.UpdatedAt)
+	}
+}
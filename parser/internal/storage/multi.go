@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"pricerunner-parser/internal/models"
+)
+
+// MultiStorage fans out writes to several storages.
+// Reads are served by the first (primary) storage.
+type MultiStorage struct {
+	backends []Storage
+}
+
+// NewMultiStorage creates a storage writing to all given backends
+func NewMultiStorage(backends ...Storage) *MultiStorage {
+	return &MultiStorage{
+		backends: backends,
+	}
+}
+
+// SaveProducts saves products to every backend
+func (m *MultiStorage) SaveProducts(products []models.Product, pageNumber int) error {
+	var errs []error
+	for _, backend := range m.backends {
+		if err := backend.SaveProducts(products, pageNumber); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SaveFinalData saves final data to every backend
+func (m *MultiStorage) SaveFinalData(products []models.Product) error {
+	var errs []error
+	for _, backend := range m.backends {
+		if err := backend.SaveFinalData(products); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UpdatePrices updates prices in every backend
+func (m *MultiStorage) UpdatePrices(updates []models.PriceUpdate) error {
+	var errs []error
+	for _, backend := range m.backends {
+		if err := backend.UpdatePrices(updates); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ProductExists checks the primary backend
+func (m *MultiStorage) ProductExists(productID string) (bool, error) {
+	return m.backends[0].ProductExists(productID)
+}
+
+// GetExistingProducts returns product IDs from the primary backend
+func (m *MultiStorage) GetExistingProducts() ([]string, error) {
+	return m.backends[0].GetExistingProducts()
+}
+
+// GetProductUpdateTimes returns update times from the primary backend
+func (m *MultiStorage) GetProductUpdateTimes() (map[string]time.Time, error) {
+	return m.backends[0].GetProductUpdateTimes()
+}
+
+// Close closes all backends
+func (m *MultiStorage) Close() error {
+	var errs []error
+	for _, backend := range m.backends {
+		if err := backend.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
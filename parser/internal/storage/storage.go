@@ -1,6 +1,10 @@
 package storage
 
-import "pricerunner-parser/internal/models"
+import (
+	"time"
+
+	"pricerunner-parser/internal/models"
+)
 
 // Storage defines the interface for data storage
 type Storage interface {
@@ -16,6 +20,12 @@ type Storage interface {
 	// GetExistingProducts returns a list of existing product IDs
 	GetExistingProducts() ([]string, error)
 
+	// GetProductUpdateTimes returns the time of the last full parse for each product
+	GetProductUpdateTimes() (map[string]time.Time, error)
+
+	// UpdatePrices updates current prices and keeps the previous ones
+	UpdatePrices(updates []models.PriceUpdate) error
+
 	// Close closes any open connections
 	Close() error
 }
@@ -0,0 +1,123 @@
+package variant
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// colorKeys and sizeKeys are feature names holding the colour and size of a product
+var (
+	colorKeys = map[string]bool{"color": true, "colour": true}
+	sizeKeys  = map[string]bool{"size": true, "shoe size": true, "clothing size": true, "screen size": true}
+)
+
+// colorWords are words that make a title suffix like "- Space Grey" a colour
+var colorWords = map[string]bool{
+	"black": true, "white": true, "grey": true, "gray": true, "silver": true, "gold": true,
+	"red": true, "blue": true, "navy": true, "green": true, "yellow": true, "orange": true,
+	"pink": true, "purple": true, "brown": true, "beige": true, "cream": true, "titanium": true,
+	"graphite": true, "midnight": true, "starlight": true,
+}
+
+// wordPattern splits a colour suffix into words
+var wordPattern = regexp.MustCompile(`[\p{L}]+`)
+
+// Info describes the product family of a product and what sets it apart from its siblings.
+// Products of one family share the GroupID, their Color and Size differ.
+type Info struct {
+	GroupID string
+	Color   string
+	Size    string
+}
+
+// IsEmpty reports whether no variant attribute was found
+func (i Info) IsEmpty() bool {
+	return i.Color == "" && i.Size == ""
+}
+
+// Extract finds the colour and size of a product in its features and title,
+// and derives the family from the title without them.
+func Extract(title, category string, features map[string]string) Info {
+	var info Info
+	for name, value := range features {
+		// Ключи вида "Design: Colour" - берем название после категории
+		if idx := strings.LastIndex(name, ": "); idx >= 0 {
+			name = name[idx+2:]
+		}
+		key := strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case colorKeys[key]:
+			info.Color = strings.TrimSpace(value)
+		case sizeKeys[key]:
+			info.Size = strings.TrimSpace(value)
+		}
+	}
+
+	base := title
+	// Источники пишут цвет после последнего " - ": "530 Bungee - Moonbeam/Phantom"
+	if i := strings.LastIndex(base, " - "); i > 0 {
+		suffix := strings.TrimSpace(base[i+3:])
+		if isColor(suffix) || (info.Color != "" && strings.EqualFold(suffix, info.Color)) {
+			if info.Color == "" {
+				info.Color = suffix
+			}
+			base = base[:i]
+		}
+	}
+	base = removeFold(base, info.Color)
+	base = removeFold(base, info.Size)
+
+	info.GroupID = groupID(category, base)
+	return info
+}
+
+// isColor reports whether a title suffix names a colour or a colour combination
+func isColor(suffix string) bool {
+	if suffix == "" || strings.ContainsAny(suffix, "0123456789") {
+		return false
+	}
+	words := wordPattern.FindAllString(suffix, -1)
+	if len(words) == 0 || len(words) > 4 {
+		return false
+	}
+	if strings.Contains(suffix, "/") {
+		return true
+	}
+	for _, word := range words {
+		if colorWords[strings.ToLower(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFold removes value from title ignoring case, the title is kept if nothing would remain
+func removeFold(title, value string) string {
+	if value == "" {
+		return title
+	}
+	lower, needle := strings.ToLower(title), strings.ToLower(value)
+	if len(lower) != len(title) || len(needle) != len(value) {
+		// Смещения в нижнем регистре не совпадают с исходными
+		lower, needle = title, value
+	}
+	i := strings.Index(lower, needle)
+	if i < 0 {
+		return title
+	}
+	rest := title[:i] + title[i+len(needle):]
+	if strings.Trim(rest, " ,-/") == "" {
+		return title
+	}
+	return rest
+}
+
+// groupID returns a stable ID of the normalized base title within a category
+func groupID(category, base string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(base)), " ")
+	normalized = strings.Trim(normalized, " ,-/")
+	sum := sha1.Sum([]byte(strings.ToLower(category) + "\n" + normalized))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,121 @@
+package variant
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		category string
+		features map[string]string
+		color    string
+		size     string
+	}{
+		{
+			name:     "colour suffix",
+			title:    "New Balance Little Kid's 530 Bungee - Moonbeam/Phantom",
+			category: "Kids Shoes",
+			color:    "Moonbeam/Phantom",
+		},
+		{
+			name:     "colour word suffix",
+			title:    "New Balance Little Kid's 530 Bungee - Blue/White",
+			category: "Kids Shoes",
+			color:    "Blue/White",
+		},
+		{
+			name:     "colour feature in title",
+			title:    "Apple iPhone 16 Pro Max, 256GB Desert Titanium",
+			category: "Smartphones",
+			features: map[string]string{"Colour": "Desert Titanium"},
+			color:    "Desert Titanium",
+		},
+		{
+			name:     "prefixed colour feature",
+			title:    "Apple iPhone 16 Pro Max, 256GB Black Titanium",
+			category: "Smartphones",
+			features: map[string]string{"Design: Colour": "Black Titanium", "General: Brand": "Apple"},
+			color:    "Black Titanium",
+		},
+		{
+			name:     "prefixed size feature",
+			title:    "Samsung Crystal UHD CU7100 55\"",
+			category: "TVs",
+			features: map[string]string{"Size: Screen size": "55\""},
+			size:     "55\"",
+		},
+		{
+			name:     "no variant",
+			title:    "Apple iPhone 15 128GB",
+			category: "Smartphones",
+			features: map[string]string{"Memory: Storage capacity": "128 GB"},
+		},
+		{
+			name:     "model number suffix is not a colour",
+			title:    "Sony WH-1000XM5 - 2022",
+			category: "Headphones",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := Extract(test.title, test.category, test.features)
+			if info.Color != test.color || info.Size != test.size {
+				t.Errorf("expected colour %q size %q, got colour %q size %q", test.color, test.size, info.Color, info.Size)
+			}
+			if info.GroupID == "" {
+				t.Error("empty group ID")
+			}
+			if info.IsEmpty() != (test.color == "" && test.size == "") {
+				t.Errorf("IsEmpty = %v", info.IsEmpty())
+			}
+		})
+	}
+}
+
+func TestExtractFamilies(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  Info
+		equal bool
+	}{
+		{
+			name:  "shoe colours",
+			a:     Extract("New Balance Little Kid's 530 Bungee - Moonbeam/Phantom", "Kids Shoes", nil),
+			b:     Extract("New Balance Little Kid's 530 Bungee - Blue/White", "Kids Shoes", nil),
+			equal: true,
+		},
+		{
+			name:  "phone colours from plain and prefixed features",
+			a:     Extract("Apple iPhone 16 Pro Max, 256GB Desert Titanium", "Smartphones", map[string]string{"Colour": "Desert Titanium"}),
+			b:     Extract("Apple iPhone 16 Pro Max, 256GB Black Titanium", "Smartphones", map[string]string{"Design: Colour": "Black Titanium"}),
+			equal: true,
+		},
+		{
+			name:  "TV sizes",
+			a:     Extract("Samsung Crystal UHD CU7100 55\"", "TVs", map[string]string{"Size: Screen size": "55\""}),
+			b:     Extract("Samsung Crystal UHD CU7100 65\"", "TVs", map[string]string{"Size: Screen size": "65\""}),
+			equal: true,
+		},
+		{
+			name:  "same title in another category",
+			a:     Extract("New Balance Little Kid's 530 Bungee - Blue/White", "Kids Shoes", nil),
+			b:     Extract("New Balance Little Kid's 530 Bungee - Blue/White", "Sneakers", nil),
+			equal: false,
+		},
+		{
+			name:  "different storage",
+			a:     Extract("Apple iPhone 15 128GB", "Smartphones", nil),
+			b:     Extract("Apple iPhone 15 256GB", "Smartphones", nil),
+			equal: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if (test.a.GroupID == test.b.GroupID) != test.equal {
+				t.Errorf("group IDs %s and %s: expected equal %v", test.a.GroupID, test.b.GroupID, test.equal)
+			}
+		})
+	}
+}
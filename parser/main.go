@@ -2,22 +2,31 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"log"
 	"net/http"
+	"os"
 	"pricerunner-parser/internal/config"
+	"pricerunner-parser/internal/downloader"
+	"pricerunner-parser/internal/fetch"
 	"pricerunner-parser/internal/parser"
+	"pricerunner-parser/internal/runreport"
+	"pricerunner-parser/internal/source"
 	"pricerunner-parser/internal/storage"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
 
+// ParserController runs one parsing job at a time, job is the ID of the running one
 type ParserController struct {
 	mu     sync.Mutex
 	parser *parser.Parser
 	status string
+	job    string
 	cfg    *config.Config
 }
 
@@ -26,7 +35,15 @@ func NewParserController(cfg *config.Config) *ParserController {
 	if err != nil {
 		log.Fatalf("Failed to create storage: %v", err)
 	}
-	p := parser.New(cfg, store)
+	src, err := source.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create source: %v", err)
+	}
+	images, err := downloader.NewObjectStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to create image store: %v", err)
+	}
+	p := parser.New(cfg, store, src, images)
 	return &ParserController{
 		parser: p,
 		status: "idle",
@@ -34,45 +51,100 @@ func NewParserController(cfg *config.Config) *ParserController {
 	}
 }
 
-func (c *ParserController) runParsing() {
-	c.mu.Lock()
-	if c.status == "running" {
-		c.mu.Unlock()
+func (c *ParserController) runParsing(job parser.Job) {
+	if job.ID == "" {
+		job.ID = runreport.NewJobID()
+	}
+	if !c.begin(job) {
 		log.Println("Parser is already running")
 		return
 	}
+	c.run(job)
+}
+
+// begin marks the parser as running a job, false if another job is running or stopping.
+// The check and the update happen under one lock, so two starts can't both succeed.
+func (c *ParserController) begin(job parser.Job) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status != "idle" {
+		return false
+	}
 	c.status = "running"
-	c.mu.Unlock()
+	c.job = job.ID
+	return true
+}
 
+// run parses a job registered with begin and marks the parser idle afterwards
+func (c *ParserController) run(job parser.Job) {
 	log.Println("Starting parser...")
-	if err := c.parser.Parse(); err != nil {
+	if err := c.parser.Parse(job); err != nil {
 		log.Printf("Parsing failed: %v", err)
 	}
 	log.Println("Parsing completed.")
 
 	c.mu.Lock()
 	c.status = "idle"
+	c.job = ""
 	c.mu.Unlock()
 }
 
 func (c *ParserController) startHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		URL      string `json:"url"`
-		Category string `json:"category"`
-	}
+	var req parser.Job
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// In a real application, you would update the config or pass these to the parser
-	log.Printf("Manual start for URL: %s, Category: %s", req.URL, req.Category)
+	if req.ID != "" && !runreport.ValidJobID(req.ID) {
+		http.Error(w, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+	if req.ID == "" {
+		req.ID = runreport.NewJobID()
+	}
 
-	go c.runParsing()
+	if !c.begin(req) {
+		http.Error(w, "Parser is already running", http.StatusConflict)
+		return
+	}
+
+	log.Printf("Manual start for URL: %s, Category: %s, Job: %s", req.URL, req.Category, req.ID)
+
+	go c.run(req)
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "Parser started"})
+	json.NewEncoder(w).Encode(map[string]string{"status": "Parser started", "job_id": req.ID})
+}
+
+// jobsHandler serves GET /jobs/{id}/report
+func (c *ParserController) jobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/report")
+	if !ok || jobID == "" || strings.Contains(jobID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	report, err := c.parser.RunReport(jobID)
+	if errors.Is(err, runreport.ErrNotFound) {
+		http.Error(w, "Report not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to load report of job %s: %v", jobID, err)
+		http.Error(w, "Failed to load report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
 }
 
 func (c *ParserController) stopHandler(w http.ResponseWriter, r *http.Request) {
@@ -97,7 +169,36 @@ func (c *ParserController) statusHandler(w http.ResponseWriter, r *http.Request)
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": c.status})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   c.status,
+		"job_id":   c.job,
+		"last_run": c.parser.LastReport(),
+		"proxies":  c.parser.ProxyStats(),
+		"blocked":  c.parser.BlockedURLs(),
+	})
+}
+
+func (c *ParserController) selftestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req parser.SelfTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.parser.SelfTest(req)
+	if err != nil {
+		log.Printf("Self-test failed: %v", err)
+		http.Error(w, "Self-test failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
 func (c *ParserController) startScheduler() {
@@ -105,7 +206,7 @@ func (c *ParserController) startScheduler() {
 	go func() {
 		for range ticker.C {
 			log.Println("Starting scheduled parsing...")
-			c.runParsing()
+			c.runParsing(parser.Job{})
 		}
 	}()
 }
@@ -120,15 +221,40 @@ func initPlaywright() error {
 	return nil
 }
 
-func main() {
-	// Инициализируем Playwright в самом начале
-	log.Println("Initializing Playwright...")
-	if err := initPlaywright(); err != nil {
-		log.Printf("Warning: Playwright initialization failed: %v", err)
-		// Продолжаем работу, возможно браузеры уже установлены
+// runGolden checks fixture extraction against golden files and returns the exit code
+func runGolden(cfg *config.Config, update bool) int {
+	src, err := source.New(cfg)
+	if err != nil {
+		log.Printf("Failed to create source: %v", err)
+		return 1
+	}
+
+	// Golden проверки не пишут в хранилище
+	p := parser.New(cfg, storage.NewJSONStorage(os.TempDir()), src, downloader.NewFileStore(os.TempDir()))
+	results, err := p.RunGolden(update)
+	if err != nil {
+		log.Printf("Golden check failed: %v", err)
+		return 1
 	}
 
+	failed := 0
+	for _, result := range results {
+		if !result.OK {
+			failed++
+		}
+	}
+	log.Printf("Golden check: %d pages, %d failed", len(results), failed)
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func main() {
 	configPath := flag.String("config", "config.yaml", "Path to configuration file")
+	golden := flag.Bool("golden", false, "Compare extraction of saved fixtures with golden files and exit")
+	updateGolden := flag.Bool("update-golden", false, "Rewrite golden files from saved fixtures and exit")
 	flag.Parse()
 
 	cfg, err := config.Load(*configPath)
@@ -136,12 +262,27 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Браузер нужен сразу только источникам в режиме browser, статические ставят его при откате
+	if cfg.Parser.SourceFetchMode() == fetch.ModeBrowser {
+		log.Println("Initializing Playwright...")
+		if err := initPlaywright(); err != nil {
+			log.Printf("Warning: Playwright initialization failed: %v", err)
+			// Продолжаем работу, возможно браузеры уже установлены
+		}
+	}
+
+	if *golden || *updateGolden {
+		os.Exit(runGolden(cfg, *updateGolden))
+	}
+
 	controller := NewParserController(cfg)
 	controller.startScheduler()
 
 	http.HandleFunc("/start", controller.startHandler)
 	http.HandleFunc("/stop", controller.stopHandler)
 	http.HandleFunc("/status", controller.statusHandler)
+	http.HandleFunc("/selftest", controller.selftestHandler)
+	http.HandleFunc("/jobs/", controller.jobsHandler)
 
 	log.Println("Parser API server starting on :8082")
 	if err := http.ListenAndServe(":8082", nil); err != nil {